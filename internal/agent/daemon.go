@@ -31,8 +31,13 @@ func RunDaemon(cfg *config.Config) error {
 		logger.Printf("Warning: failed to seed default sources: %v", err)
 	}
 
-	// Build LLM client and policy manager.
-	llmClient := llm.NewClient(cfg.LLMProvider, cfg.ActiveAPIKey(), cfg.DefaultModel)
+	// Build LLM client and policy manager. Unchanged chunks of a changed
+	// document are served from the cache.
+	llmOpts := cfg.LLMOptions()
+	if ttl := cfg.LLMCacheTTL(); ttl > 0 {
+		llmOpts.Cache = store.NewLLMCache(ttl)
+	}
+	llmClient := llm.New(llmOpts)
 	mgr := policy.NewManager(llmClient)
 	fetcher := policy.NewFetcher()
 
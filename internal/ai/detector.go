@@ -19,28 +19,43 @@ type Detection struct {
 	DetectionMethod string  `json:"detection_method"`
 }
 
-// FileReader abstracts file access for both local and remote scanning.
-type FileReader interface {
-	ListFiles() ([]string, error)
-	ReadFile(path string) (string, error)
+// maxCodeFiles caps how many code files are searched for framework imports.
+const maxCodeFiles = 50
+
+// depFileNames are the dependency manifests searched for framework names.
+var depFileNames = map[string]bool{
+	"requirements.txt": true, "setup.py": true, "pyproject.toml": true,
+	"package.json": true, "go.mod": true, ".env": true, ".env.example": true,
 }
 
-// Detector scans a file tree for AI/ML framework usage.
-type Detector struct{}
+// codeExts are the extensions of code files searched for framework imports.
+var codeExts = map[string]bool{".py": true, ".js": true, ".ts": true, ".go": true, ".java": true, ".rs": true}
 
-func NewDetector() *Detector {
-	return &Detector{}
+// Detector scans a file tree for AI/ML framework usage. Detection runs in
+// four phases: model file extensions and AI config files are found by path
+// (ScanPaths), dependency manifests and code imports by content
+// (ScanContent), and Merge combines the results.
+type Detector struct {
+	patterns map[string][]*regexp.Regexp
 }
 
-// Scan runs the 4-phase detection algorithm and returns all detections.
-func (d *Detector) Scan(reader FileReader) ([]Detection, error) {
-	files, err := reader.ListFiles()
-	if err != nil {
-		return nil, err
+func NewDetector() *Detector {
+	patterns := make(map[string][]*regexp.Regexp, len(AIFrameworks))
+	for key, fw := range AIFrameworks {
+		for _, pattern := range fw.Patterns {
+			re, err := regexp.Compile("(?i)" + pattern)
+			if err != nil {
+				continue
+			}
+			patterns[key] = append(patterns[key], re)
+		}
 	}
+	return &Detector{patterns: patterns}
+}
 
+// ScanPaths runs the path-based phases: model files and AI config files.
+func (d *Detector) ScanPaths(files []string) []Detection {
 	var detections []Detection
-	seen := make(map[string]bool)
 
 	// Phase 1: Model file extensions
 	for _, path := range files {
@@ -83,31 +98,42 @@ func (d *Detector) Scan(reader FileReader) ([]Detection, error) {
 		}
 	}
 
-	// Phase 3: Dependency file scanning
-	depFileNames := map[string]bool{
-		"requirements.txt": true, "setup.py": true, "pyproject.toml": true,
-		"package.json": true, "go.mod": true, ".env": true, ".env.example": true,
-	}
+	return detections
+}
+
+// ContentFiles selects the files whose contents ScanContent needs: every
+// dependency manifest and the first maxCodeFiles code files.
+func (d *Detector) ContentFiles(files []string) map[string]bool {
+	selected := make(map[string]bool)
+	codeFiles := 0
 	for _, path := range files {
-		fname := filepath.Base(path)
-		if !depFileNames[fname] {
-			continue
+		if depFileNames[filepath.Base(path)] {
+			selected[path] = true
 		}
-		content, err := reader.ReadFile(path)
-		if err != nil {
-			continue
+		if codeFiles < maxCodeFiles && codeExts[strings.ToLower(filepath.Ext(path))] {
+			selected[path] = true
+			codeFiles++
 		}
+	}
+	return selected
+}
+
+// ScanContent runs the content-based phases on one file: framework names in
+// dependency manifests and framework imports in code. It reports every
+// framework found; Merge keeps one detection per framework. ScanContent is
+// safe for concurrent use.
+func (d *Detector) ScanContent(path, content string) []Detection {
+	var detections []Detection
+
+	// Phase 3: Dependency file scanning
+	if depFileNames[filepath.Base(path)] {
 		contentLower := strings.ToLower(content)
-		for key, fw := range AIFrameworks {
-			if seen[key] {
-				continue
-			}
+		for _, fw := range AIFrameworks {
 			for _, dep := range fw.DepNames {
 				if strings.Contains(contentLower, strings.ToLower(dep)) {
-					version := extractVersion(content, dep)
 					detections = append(detections, Detection{
 						Name:            fw.Name,
-						Version:         version,
+						Version:         extractVersion(content, dep),
 						Type:            fw.Type,
 						RiskLevel:       fw.RiskBase,
 						EUAIActRisk:     classifyEUAIActRisk(fw.Type),
@@ -116,40 +142,16 @@ func (d *Detector) Scan(reader FileReader) ([]Detection, error) {
 						Confidence:      0.95,
 						DetectionMethod: "dependency",
 					})
-					seen[key] = true
 					break
 				}
 			}
 		}
 	}
 
-	// Phase 4: Import pattern matching in code files (limited to 50 files)
-	codeExts := map[string]bool{".py": true, ".js": true, ".ts": true, ".go": true, ".java": true, ".rs": true}
-	var codeFiles []string
-	for _, path := range files {
-		ext := strings.ToLower(filepath.Ext(path))
-		if codeExts[ext] {
-			codeFiles = append(codeFiles, path)
-		}
-		if len(codeFiles) >= 50 {
-			break
-		}
-	}
-
-	for _, path := range codeFiles {
-		content, err := reader.ReadFile(path)
-		if err != nil {
-			continue
-		}
+	// Phase 4: Import pattern matching in code files
+	if codeExts[strings.ToLower(filepath.Ext(path))] {
 		for key, fw := range AIFrameworks {
-			if seen[key] {
-				continue
-			}
-			for _, pattern := range fw.Patterns {
-				re, err := regexp.Compile("(?i)" + pattern)
-				if err != nil {
-					continue
-				}
+			for _, re := range d.patterns[key] {
 				if re.MatchString(content) {
 					detections = append(detections, Detection{
 						Name:            fw.Name,
@@ -161,14 +163,33 @@ func (d *Detector) Scan(reader FileReader) ([]Detection, error) {
 						Confidence:      0.85,
 						DetectionMethod: "code_pattern",
 					})
-					seen[key] = true
 					break
 				}
 			}
 		}
 	}
 
-	return detections, nil
+	return detections
+}
+
+// Merge combines the path detections with the content detections of each
+// file, given in path order. Each framework is reported once, preferring a
+// dependency declaration over an import.
+func (d *Detector) Merge(pathDetections []Detection, contentDetections [][]Detection) []Detection {
+	detections := append([]Detection(nil), pathDetections...)
+	seen := make(map[string]bool)
+	for _, method := range []string{"dependency", "code_pattern"} {
+		for _, file := range contentDetections {
+			for _, det := range file {
+				if det.DetectionMethod != method || seen[det.Name] {
+					continue
+				}
+				seen[det.Name] = true
+				detections = append(detections, det)
+			}
+		}
+	}
+	return detections
 }
 
 func extractModelName(path string) string {
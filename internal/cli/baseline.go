@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/nerifect/nerifect-cli/internal/compliance"
+	"github.com/nerifect/nerifect-cli/internal/config"
+	"github.com/nerifect/nerifect-cli/internal/output"
+	"github.com/nerifect/nerifect-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+func newBaselineCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "baseline",
+		Short: "Manage violation baselines",
+		Long: `A baseline records the violations of an existing scan so that later
+scans with --baseline only fail on findings that are new.`,
+	}
+	cmd.AddCommand(newBaselineCreateCmd())
+	return cmd
+}
+
+func newBaselineCreateCmd() *cobra.Command {
+	var outFile string
+
+	cmd := &cobra.Command{
+		Use:   "create <scan-id>",
+		Short: "Write a baseline file from a scan's violations",
+		Long: `Write the fingerprints of a scan's violations to a baseline file. Commit the
+file and pass it to 'nerifect scan --baseline' to ignore these findings.`,
+		Example: `  nerifect baseline create 12
+  nerifect baseline create 12 -o .nerifect-baseline.json
+  nerifect scan . --baseline .nerifect-baseline.json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runBaselineCreate(args[0], outFile)
+		},
+	}
+
+	// Shadows the global --output/-o format flag: here it names the file to write
+	cmd.Flags().StringVarP(&outFile, "output", "o", compliance.DefaultBaselineFile, "baseline file to write")
+	return cmd
+}
+
+func runBaselineCreate(scanIDStr, outFile string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	if _, err := store.Open(cfg.DatabasePath); err != nil {
+		return fmt.Errorf("initializing database: %w", err)
+	}
+
+	scanID, err := strconv.ParseInt(scanIDStr, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid scan ID: %s", scanIDStr)
+	}
+	scan, err := store.GetScan(scanID)
+	if err != nil {
+		return fmt.Errorf("scan #%d not found: %w", scanID, err)
+	}
+	violations, err := store.GetViolationsByScan(scanID)
+	if err != nil {
+		return fmt.Errorf("loading violations for scan #%d: %w", scanID, err)
+	}
+
+	baseline := compliance.NewBaseline(scan, violations)
+	if err := baseline.Save(outFile); err != nil {
+		return fmt.Errorf("writing baseline: %w", err)
+	}
+
+	output.PrintSuccess(fmt.Sprintf("Baselined %d violations from scan #%d in %s", len(baseline.Entries), scanID, outFile))
+	return nil
+}
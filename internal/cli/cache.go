@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/nerifect/nerifect-cli/internal/config"
+	"github.com/nerifect/nerifect-cli/internal/output"
+	"github.com/nerifect/nerifect-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+func newCacheCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the LLM response cache",
+		Long: `Compliance evaluations and policy extractions are cached by provider, model,
+prompt version, rules and file content, so unchanged inputs are not sent to
+the LLM again. Entries expire after llm_cache_ttl_hours; 0 disables the cache.
+Use --no-cache on scan or policy add to bypass it for one run.`,
+	}
+	cmd.AddCommand(newCacheStatsCmd())
+	cmd.AddCommand(newCacheClearCmd())
+	return cmd
+}
+
+func newCacheStatsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "stats",
+		Short: "Show cache size and hit counts",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := openCacheStore()
+			if err != nil {
+				return err
+			}
+			stats, err := store.GetCacheStats(cfg.LLMCacheTTL())
+			if err != nil {
+				return fmt.Errorf("reading cache: %w", err)
+			}
+			output.RenderCacheStats(stats, cfg.LLMCacheTTL(), output.ParseFormat(outputFormat))
+			return nil
+		},
+	}
+}
+
+func newCacheClearCmd() *cobra.Command {
+	var expired bool
+
+	cmd := &cobra.Command{
+		Use:   "clear",
+		Short: "Delete cached LLM responses",
+		Example: `  nerifect cache clear
+  nerifect cache clear --expired`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := openCacheStore()
+			if err != nil {
+				return err
+			}
+			n, err := store.ClearCache(expired, cfg.LLMCacheTTL())
+			if err != nil {
+				return fmt.Errorf("clearing cache: %w", err)
+			}
+			output.PrintSuccess(fmt.Sprintf("Removed %d cache entries", n))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&expired, "expired", false, "only delete entries older than llm_cache_ttl_hours")
+	return cmd
+}
+
+func openCacheStore() (*config.Config, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("loading config: %w", err)
+	}
+	if _, err := store.Open(cfg.DatabasePath); err != nil {
+		return nil, fmt.Errorf("initializing database: %w", err)
+	}
+	return cfg, nil
+}
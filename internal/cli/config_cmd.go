@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/nerifect/nerifect-cli/internal/config"
+	"github.com/nerifect/nerifect-cli/internal/llm"
 	"github.com/nerifect/nerifect-cli/internal/output"
 	"github.com/spf13/cobra"
 )
@@ -60,6 +61,9 @@ func newConfigSetCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			if args[0] == "llm_provider" && !llm.IsValidProvider(args[1]) {
+				return fmt.Errorf("unknown LLM provider %q (valid: %s)", args[1], strings.Join(llm.ProviderNames(), ", "))
+			}
 			if err := cfg.Set(args[0], args[1]); err != nil {
 				return err
 			}
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/nerifect/nerifect-cli/internal/compliance"
+	"github.com/nerifect/nerifect-cli/internal/config"
+	"github.com/nerifect/nerifect-cli/internal/output"
+	"github.com/nerifect/nerifect-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+func newDiffCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "diff <scan-a> <scan-b>",
+		Short: "Compare the findings of two scans",
+		Long: `Compare two scans and report new, fixed and unchanged violations, the score
+change, and added or removed AI detections. Violations are matched by
+fingerprint (rule, file and code snippet), so findings that only moved to
+another line count as unchanged.
+
+Use --output markdown to paste the comparison into a pull request.`,
+		Example: `  nerifect diff 3 7
+  nerifect diff 3 7 --output json
+  nerifect diff 3 7 --output markdown`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDiff(args[0], args[1])
+		},
+	}
+}
+
+func runDiff(baseArg, headArg string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	if _, err := store.Open(cfg.DatabasePath); err != nil {
+		return fmt.Errorf("initializing database: %w", err)
+	}
+
+	base, err := loadScanSnapshot(baseArg)
+	if err != nil {
+		return err
+	}
+	head, err := loadScanSnapshot(headArg)
+	if err != nil {
+		return err
+	}
+
+	output.RenderScanDiff(compliance.CompareScans(*base, *head), output.ParseFormat(outputFormat))
+	return nil
+}
+
+// loadScanSnapshot loads a scan with its violations and AI detections.
+func loadScanSnapshot(idArg string) (*compliance.ScanSnapshot, error) {
+	scanID, err := strconv.ParseInt(idArg, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid scan ID: %s", idArg)
+	}
+	scan, err := store.GetScan(scanID)
+	if err != nil {
+		return nil, fmt.Errorf("scan #%d not found: %w", scanID, err)
+	}
+	violations, err := store.GetViolationsByScan(scanID)
+	if err != nil {
+		return nil, fmt.Errorf("loading violations for scan #%d: %w", scanID, err)
+	}
+	detections, err := store.GetAIDetectionsByScan(scanID)
+	if err != nil {
+		return nil, fmt.Errorf("loading AI detections for scan #%d: %w", scanID, err)
+	}
+	return &compliance.ScanSnapshot{Scan: scan, Violations: violations, Detections: detections}, nil
+}
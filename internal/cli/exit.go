@@ -0,0 +1,21 @@
+package cli
+
+import "fmt"
+
+// ExitError ends the process with a specific exit code instead of the default
+// of 1. Err, if set, is reported like any other command error.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("exit status %d", e.Code)
+	}
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
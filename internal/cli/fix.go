@@ -1,39 +1,158 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 
+	"github.com/charmbracelet/huh"
 	"github.com/nerifect/nerifect-cli/internal/config"
 	"github.com/nerifect/nerifect-cli/internal/fixer"
 	"github.com/nerifect/nerifect-cli/internal/llm"
 	"github.com/nerifect/nerifect-cli/internal/output"
+	"github.com/nerifect/nerifect-cli/internal/scanner"
 	"github.com/nerifect/nerifect-cli/internal/store"
 	"github.com/spf13/cobra"
 )
 
+// applyOptions controls how a generated fix is written to disk.
+type applyOptions struct {
+	dir string // local checkout to apply to; defaults to the scan target
+	yes bool   // skip the confirmation prompt
+}
+
 func newFixCmd() *cobra.Command {
-	var fixAll bool
+	var fixAll, apply bool
+	var opts applyOptions
 
 	cmd := &cobra.Command{
 		Use:   "fix <violation-id>",
 		Short: "Generate an AI-powered fix for a violation",
 		Long: `Generate a compliance fix using AI. Pass a violation ID to fix a single
-violation, or use --all with a scan ID to fix all violations.`,
+violation, or use --all with a scan ID to fix all violations.
+With --apply, each generated fix is previewed and written to the scanned
+working tree after confirmation.`,
 		Example: `  nerifect fix 42
-  nerifect fix --all 1`,
+  nerifect fix 42 --apply
+  nerifect fix --all 1
+  nerifect fix apply 7`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runFix(cmd, args[0], fixAll)
+			return runFix(cmd, args[0], fixAll, apply, opts)
 		},
 	}
 
 	cmd.Flags().BoolVar(&fixAll, "all", false, "fix all violations in a scan (argument is scan-id)")
+	cmd.Flags().BoolVar(&apply, "apply", false, "apply generated fixes to the working tree")
+	cmd.Flags().StringVar(&opts.dir, "path", "", "local checkout to apply fixes to (default: the scanned directory)")
+	cmd.Flags().BoolVarP(&opts.yes, "yes", "y", false, "apply without asking for confirmation")
+
+	cmd.AddCommand(newFixApplyCmd())
+	cmd.AddCommand(newFixListCmd())
+	cmd.AddCommand(newFixShowCmd())
+	cmd.AddCommand(newFixApproveCmd())
+	cmd.AddCommand(newFixRejectCmd())
+	return cmd
+}
+
+func newFixListCmd() *cobra.Command {
+	var scanID int64
+	var status string
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List generated fixes",
+		Long:  `List generated fixes with their review status, newest first.`,
+		Example: `  nerifect fix list
+  nerifect fix list --scan 3
+  nerifect fix list --status pending`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runFixList(scanID, status)
+		},
+	}
+
+	cmd.Flags().Int64Var(&scanID, "scan", 0, "only show fixes for this scan ID")
+	cmd.Flags().StringVar(&status, "status", "", "only show fixes with this status: pending, approved, applied, rejected")
+	return cmd
+}
+
+func newFixShowCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show <fix-id>",
+		Short: "Show a generated fix and its diff",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runFixShow(args[0])
+		},
+	}
+}
+
+func newFixApproveCmd() *cobra.Command {
+	var note string
+
+	cmd := &cobra.Command{
+		Use:   "approve <fix-id>",
+		Short: "Approve a generated fix",
+		Example: `  nerifect fix approve 7
+  nerifect fix approve 7 --note "checked against staging config"`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runFixReview(args[0], store.FixStatusApproved, note)
+		},
+	}
+
+	cmd.Flags().StringVar(&note, "note", "", "optional review note")
 	return cmd
 }
 
-func runFix(cmd *cobra.Command, idStr string, fixAll bool) error {
+func newFixRejectCmd() *cobra.Command {
+	var reason string
+
+	cmd := &cobra.Command{
+		Use:     "reject <fix-id>",
+		Short:   "Reject a generated fix",
+		Example: `  nerifect fix reject 7 --reason "breaks the healthcheck"`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if strings.TrimSpace(reason) == "" {
+				return fmt.Errorf("--reason is required when rejecting a fix")
+			}
+			return runFixReview(args[0], store.FixStatusRejected, reason)
+		},
+	}
+
+	cmd.Flags().StringVar(&reason, "reason", "", "why the fix was rejected (required)")
+	return cmd
+}
+
+func newFixApplyCmd() *cobra.Command {
+	var opts applyOptions
+
+	cmd := &cobra.Command{
+		Use:   "apply <fix-id>",
+		Short: "Apply a generated fix to the working tree",
+		Long: `Apply a previously generated fix to the file it targets. The diff is
+previewed before the file is written, and the fix is marked APPLIED.`,
+		Example: `  nerifect fix apply 7
+  nerifect fix apply 7 --yes
+  nerifect fix apply 7 --path /path/to/checkout`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runFixApply(args[0], opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.dir, "path", "", "local checkout to apply the fix to (default: the scanned directory)")
+	cmd.Flags().BoolVarP(&opts.yes, "yes", "y", false, "apply without asking for confirmation")
+	return cmd
+}
+
+func runFix(cmd *cobra.Command, idStr string, fixAll, apply bool, opts applyOptions) error {
 	cfg, err := config.Load()
 	if err != nil {
 		return err
@@ -50,74 +169,401 @@ func runFix(cmd *cobra.Command, idStr string, fixAll bool) error {
 		return fmt.Errorf("invalid ID: %s", idStr)
 	}
 
-	llmClient := llm.NewClient(cfg.LLMProvider, cfg.ActiveAPIKey(), cfg.DefaultModel)
+	llmClient := llm.New(cfg.LLMOptions())
 	f := fixer.NewFixer(llmClient)
 	outFmt := output.ParseFormat(outputFormat)
 
+	var applyOpts *applyOptions
+	if apply {
+		applyOpts = &opts
+	}
+
 	if fixAll {
-		return fixAllViolations(cmd, f, id, outFmt)
+		return fixAllViolations(cmd, cfg, f, id, outFmt, applyOpts)
 	}
-	return fixSingleViolation(cmd, f, id, outFmt)
+	return fixSingleViolation(cmd, cfg, f, id, outFmt, applyOpts)
 }
 
-func fixSingleViolation(cmd *cobra.Command, f *fixer.Fixer, violationID int64, outFmt output.Format) error {
+func fixSingleViolation(cmd *cobra.Command, cfg *config.Config, f *fixer.Fixer, violationID int64, outFmt output.Format, applyOpts *applyOptions) error {
 	v, err := store.GetViolation(violationID)
 	if err != nil {
 		return fmt.Errorf("violation #%d not found: %w", violationID, err)
 	}
+	if err := checkFixable(v); err != nil {
+		return err
+	}
+	scan, err := store.GetScan(v.ScanID)
+	if err != nil {
+		return fmt.Errorf("scan #%d not found: %w", v.ScanID, err)
+	}
 
 	progress := output.NewProgress(fmt.Sprintf("Generating fix for violation #%d...", violationID))
 
-	result, err := f.GenerateFix(cmd.Context(), v.RuleID, v.FilePath, string(v.Severity), v.Description, v.CodeSnippet)
+	dir, cleanup, err := scanner.OpenScanTarget(cmd.Context(), scan, cfg)
 	if err != nil {
+		progress.Fail("Reading scan target failed: " + err.Error())
+		return err
+	}
+	defer cleanup()
+
+	content, err := readViolationFile(dir, v)
+	if err != nil {
+		progress.Fail(err.Error())
+		return err
+	}
+
+	result, err := f.GenerateFix(cmd.Context(), v, content)
+	if err != nil {
+		f.RecordUsage(0)
 		progress.Fail("Fix generation failed: " + err.Error())
 		return err
 	}
 
 	fix, err := store.CreateFix(v.ID, v.ScanID, result.FixDescription, result.FixDiff, result.Confidence)
 	if err != nil {
+		f.RecordUsage(0)
 		progress.Fail("Saving fix failed: " + err.Error())
 		return err
 	}
+	f.RecordUsage(fix.ID)
 
 	progress.Done("Fix generated")
 	output.RenderFix(fix, v, outFmt)
+
+	if applyOpts != nil {
+		return applyFix(fix, v, *applyOpts)
+	}
 	return nil
 }
 
-func fixAllViolations(cmd *cobra.Command, f *fixer.Fixer, scanID int64, outFmt output.Format) error {
+func fixAllViolations(cmd *cobra.Command, cfg *config.Config, f *fixer.Fixer, scanID int64, outFmt output.Format, applyOpts *applyOptions) error {
+	scan, err := store.GetScan(scanID)
+	if err != nil {
+		return fmt.Errorf("scan #%d not found: %w", scanID, err)
+	}
 	violations, err := store.GetViolationsByScan(scanID)
 	if err != nil {
 		return fmt.Errorf("loading violations for scan #%d: %w", scanID, err)
 	}
 
+	// Suppressed violations were reviewed and accepted, so there is nothing to
+	// fix; violations from the git history are not in the current files
+	active := violations[:0]
+	historical := 0
+	for _, v := range violations {
+		switch {
+		case v.CommitSHA != "":
+			historical++
+		case v.IsActive():
+			active = append(active, v)
+		}
+	}
+	violations = active
+	if historical > 0 {
+		fmt.Fprintf(os.Stderr, "Skipping %d violations found in the git history; their lines refer to past commits\n", historical)
+	}
+
 	if len(violations) == 0 {
 		fmt.Println("No violations found for scan #", scanID)
 		return nil
 	}
 
+	dir, cleanup, err := scanner.OpenScanTarget(cmd.Context(), scan, cfg)
+	if err != nil {
+		return fmt.Errorf("reading scan target: %w", err)
+	}
+	defer cleanup()
+
 	fmt.Printf("Generating fixes for %d violations in scan #%d...\n\n", len(violations), scanID)
 
 	for i, v := range violations {
 		progress := output.NewProgress(fmt.Sprintf("[%d/%d] Fixing %s in %s...", i+1, len(violations), v.RuleID, v.FilePath))
 
-		result, err := f.GenerateFix(cmd.Context(), v.RuleID, v.FilePath, string(v.Severity), v.Description, v.CodeSnippet)
+		content, err := readViolationFile(dir, &violations[i])
 		if err != nil {
 			progress.Fail(fmt.Sprintf("Failed: %v", err))
 			fmt.Fprintf(os.Stderr, "  Skipping violation #%d: %v\n", v.ID, err)
 			continue
 		}
 
+		result, err := f.GenerateFix(cmd.Context(), &violations[i], content)
+		if err != nil {
+			f.RecordUsage(0)
+			progress.Fail(fmt.Sprintf("Failed: %v", err))
+			fmt.Fprintf(os.Stderr, "  Skipping violation #%d: %v\n", v.ID, err)
+			continue
+		}
+
 		fix, err := store.CreateFix(v.ID, v.ScanID, result.FixDescription, result.FixDiff, result.Confidence)
 		if err != nil {
+			f.RecordUsage(0)
 			progress.Fail("Save failed")
 			continue
 		}
+		f.RecordUsage(fix.ID)
 
 		progress.Done(fmt.Sprintf("Fix #%d (confidence: %.0f%%)", fix.ID, fix.Confidence*100))
+
+		if applyOpts != nil {
+			if err := applyFix(fix, &violations[i], *applyOpts); err != nil {
+				fmt.Fprintf(os.Stderr, "  Not applied: %v\n", err)
+			}
+		}
 	}
 
 	fmt.Println()
 	output.PrintSuccess(fmt.Sprintf("Fix generation complete for scan #%d", scanID))
 	return nil
 }
+
+func runFixApply(idStr string, opts applyOptions) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if _, err := store.Open(cfg.DatabasePath); err != nil {
+		return err
+	}
+
+	fix, v, err := loadFix(idStr)
+	if err != nil {
+		return err
+	}
+
+	return applyFix(fix, v, opts)
+}
+
+func runFixList(scanID int64, statusStr string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if _, err := store.Open(cfg.DatabasePath); err != nil {
+		return err
+	}
+
+	filter := store.FixFilter{ScanID: scanID}
+	if statusStr != "" {
+		status, ok := store.ParseFixStatus(statusStr)
+		if !ok {
+			return fmt.Errorf("invalid status %q (use pending, approved, applied or rejected)", statusStr)
+		}
+		filter.Status = status
+	}
+
+	fixes, err := store.ListFixes(filter)
+	if err != nil {
+		return fmt.Errorf("listing fixes: %w", err)
+	}
+
+	output.RenderFixes(fixes, output.ParseFormat(outputFormat))
+	return nil
+}
+
+func runFixShow(idStr string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if _, err := store.Open(cfg.DatabasePath); err != nil {
+		return err
+	}
+
+	fix, v, err := loadFix(idStr)
+	if err != nil {
+		return err
+	}
+
+	output.RenderFix(fix, v, output.ParseFormat(outputFormat))
+	return nil
+}
+
+func runFixReview(idStr string, status store.FixStatus, note string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if _, err := store.Open(cfg.DatabasePath); err != nil {
+		return err
+	}
+
+	fix, _, err := loadFix(idStr)
+	if err != nil {
+		return err
+	}
+
+	if fix.Status == store.FixStatusApplied {
+		return fmt.Errorf("fix #%d has already been applied", fix.ID)
+	}
+	if fix.Status == status {
+		return fmt.Errorf("fix #%d is already %s", fix.ID, strings.ToLower(string(status)))
+	}
+
+	if err := store.ReviewFix(fix.ID, status, note); err != nil {
+		return fmt.Errorf("updating fix #%d: %w", fix.ID, err)
+	}
+
+	if output.ParseFormat(outputFormat) == output.FormatJSON {
+		fix, _ = store.GetFix(fix.ID)
+		output.PrintJSON(fix)
+		return nil
+	}
+	output.PrintSuccess(fmt.Sprintf("Fix #%d %s", fix.ID, strings.ToLower(string(status))))
+	return nil
+}
+
+// loadFix parses a fix ID and loads the fix with its violation.
+func loadFix(idStr string) (*store.Fix, *store.Violation, error) {
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid fix ID: %s", idStr)
+	}
+
+	fix, err := store.GetFix(id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fix #%d not found: %w", id, err)
+	}
+
+	v, err := store.GetViolation(fix.ViolationID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("violation #%d not found: %w", fix.ViolationID, err)
+	}
+	return fix, v, nil
+}
+
+// applyFix runs a stored fix diff against the violation's file, previews the
+// resulting change, and writes it after confirmation.
+func applyFix(fix *store.Fix, v *store.Violation, opts applyOptions) error {
+	switch fix.Status {
+	case store.FixStatusApplied:
+		return fmt.Errorf("fix #%d has already been applied", fix.ID)
+	case store.FixStatusRejected:
+		return fmt.Errorf("fix #%d was rejected", fix.ID)
+	}
+	if strings.TrimSpace(fix.FixDiff) == "" {
+		return fmt.Errorf("fix #%d has no diff to apply", fix.ID)
+	}
+	if err := checkFixable(v); err != nil {
+		return err
+	}
+
+	dir := opts.dir
+	if dir == "" {
+		scan, err := store.GetScan(fix.ScanID)
+		if err != nil {
+			return fmt.Errorf("scan #%d not found: %w", fix.ScanID, err)
+		}
+		if scan.TargetType != "local" {
+			return fmt.Errorf("scan #%d targets %s; use --path to apply to a local checkout", scan.ID, scan.Target)
+		}
+		dir = scan.Target
+	}
+
+	path, err := resolveInDir(dir, v.FilePath)
+	if err != nil {
+		return err
+	}
+
+	// A missing file is created by a diff that only adds lines; ApplyPatch
+	// rejects any other diff against empty content
+	perm := fs.FileMode(0o644)
+	original := ""
+	info, err := os.Stat(path)
+	switch {
+	case err == nil:
+		perm = info.Mode().Perm()
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", v.FilePath, err)
+		}
+		original = string(data)
+	case !errors.Is(err, fs.ErrNotExist):
+		return fmt.Errorf("reading %s: %w", v.FilePath, err)
+	}
+
+	updated, err := fixer.ApplyPatch(original, fix.FixDiff)
+	if err != nil {
+		return fmt.Errorf("fix #%d does not apply to the current %s: %w", fix.ID, v.FilePath, err)
+	}
+	if original == "" && updated != "" && !strings.HasSuffix(updated, "\n") {
+		updated += "\n"
+	}
+	preview := fixer.UnifiedDiff(v.FilePath, original, updated)
+	if preview == "" {
+		return fmt.Errorf("fix #%d does not change %s", fix.ID, v.FilePath)
+	}
+
+	fmt.Println(output.HeaderStyle.Render(fmt.Sprintf("\nApplying fix #%d to %s", fix.ID, v.FilePath)))
+	fmt.Println(strings.Repeat("─", 70))
+	output.RenderDiff(preview)
+	fmt.Println()
+
+	if !opts.yes {
+		confirmed := false
+		err := huh.NewConfirm().
+			Title(fmt.Sprintf("Write changes to %s?", v.FilePath)).
+			Affirmative("Apply").
+			Negative("Cancel").
+			Value(&confirmed).
+			Run()
+		if err != nil {
+			return fmt.Errorf("apply cancelled: %w", err)
+		}
+		if !confirmed {
+			fmt.Println(output.DimStyle.Render("  Not applied."))
+			return nil
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating directory for %s: %w", v.FilePath, err)
+	}
+	if err := os.WriteFile(path, []byte(updated), perm); err != nil {
+		return fmt.Errorf("writing %s: %w", v.FilePath, err)
+	}
+	if err := store.MarkFixApplied(fix.ID); err != nil {
+		return fmt.Errorf("updating fix #%d: %w", fix.ID, err)
+	}
+
+	output.PrintSuccess(fmt.Sprintf("Fix #%d applied to %s", fix.ID, v.FilePath))
+	return nil
+}
+
+// checkFixable rejects violations found by a history scan. Their line numbers
+// refer to the commit that added the lines, and the file may have changed or
+// been deleted since, so a fix would be made against the wrong lines.
+func checkFixable(v *store.Violation) error {
+	if v.CommitSHA != "" {
+		return fmt.Errorf("violation #%d was found in commit %s of the git history and cannot be fixed in the current files", v.ID, v.CommitSHA)
+	}
+	return nil
+}
+
+// readViolationFile returns the content of the file a violation points at.
+// A file that does not exist yields an empty string, so a fix can create it
+// with a diff that only adds lines.
+func readViolationFile(dir string, v *store.Violation) (string, error) {
+	path, err := resolveInDir(dir, v.FilePath)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("reading %s: %w", v.FilePath, err)
+	}
+	return string(data), nil
+}
+
+// resolveInDir joins a scan-relative path onto dir and rejects paths that
+// would escape it.
+func resolveInDir(dir, rel string) (string, error) {
+	full := filepath.Join(dir, rel)
+	r, err := filepath.Rel(dir, full)
+	if err != nil || r == ".." || strings.HasPrefix(r, ".."+string(filepath.Separator)) || filepath.IsAbs(rel) {
+		return "", fmt.Errorf("file %q is outside %s", rel, dir)
+	}
+	return full, nil
+}
@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nerifect/nerifect-cli/internal/config"
+	"github.com/nerifect/nerifect-cli/internal/output"
+	"github.com/nerifect/nerifect-cli/internal/scanner"
+	"github.com/nerifect/nerifect-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+func newHistoryCmd() *cobra.Command {
+	var since, until string
+
+	cmd := &cobra.Command{
+		Use:   "history <repo-name-or-target>",
+		Short: "Show the compliance score trend of a target",
+		Long: `List the completed scans of a repository or path, oldest first, with the
+compliance score, active violation counts by severity and the scanned commit.
+Scans of the git history (scan --history) are left out, since their findings
+are not in the current files.
+
+The target can be the name of a configured repo, a local path or a git URL.
+--since and --until accept a date (2024-05-01), an RFC 3339 timestamp or a
+relative duration such as 30d, 2w or 12h.`,
+		Example: `  nerifect history my-repo
+  nerifect history . --since 30d
+  nerifect history https://github.com/org/repo --output json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runHistory(args[0], since, until)
+		},
+	}
+
+	cmd.Flags().StringVar(&since, "since", "", "only include scans started at or after this time")
+	cmd.Flags().StringVar(&until, "until", "", "only include scans started before this time")
+	return cmd
+}
+
+func runHistory(arg, sinceStr, untilStr string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	if _, err := store.Open(cfg.DatabasePath); err != nil {
+		return fmt.Errorf("initializing database: %w", err)
+	}
+
+	now := time.Now()
+	filter := store.ScanFilter{
+		Target: resolveHistoryTarget(cfg, arg),
+		Status: store.ScanStatusCompleted,
+		// History scans score past commits, not the working tree
+		ExcludeType: store.ScanTypeHistory,
+	}
+	if filter.Since, err = parseTimeFlag(sinceStr, now); err != nil {
+		return fmt.Errorf("invalid --since: %w", err)
+	}
+	if filter.Until, err = parseTimeFlag(untilStr, now); err != nil {
+		return fmt.Errorf("invalid --until: %w", err)
+	}
+
+	scans, err := store.FindScans(filter)
+	if err != nil {
+		return fmt.Errorf("loading scans: %w", err)
+	}
+	ids := make([]int64, len(scans))
+	for i, s := range scans {
+		ids[i] = s.ID
+	}
+	counts, err := store.CountViolationsBySeverity(ids)
+	if err != nil {
+		return fmt.Errorf("counting violations: %w", err)
+	}
+
+	entries := make([]output.HistoryEntry, len(scans))
+	for i, s := range scans {
+		entries[i] = output.HistoryEntry{Scan: s, Counts: counts[s.ID]}
+	}
+	output.RenderHistory(filter.Target, entries, output.ParseFormat(outputFormat))
+	return nil
+}
+
+// resolveHistoryTarget maps a repo name, path or URL to the target string
+// stored on its scans.
+func resolveHistoryTarget(cfg *config.Config, arg string) string {
+	if repo := cfg.FindRepo(arg); repo != nil {
+		if repo.URL != "" {
+			return scanner.RedactURL(repo.URL)
+		}
+		return repo.Path
+	}
+	if scanner.IsRemoteURL(arg, cfg) {
+		// Scans record URLs without their credentials
+		return scanner.RedactURL(arg)
+	}
+	if absPath, err := filepath.Abs(arg); err == nil {
+		return absPath
+	}
+	return arg
+}
+
+// parseTimeFlag parses a date, an RFC 3339 timestamp or a duration before now
+// such as 30d, 2w or 12h. An empty string yields the zero time.
+func parseTimeFlag(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	unit := s[len(s)-1]
+	if n, err := strconv.Atoi(s[:len(s)-1]); err == nil && n >= 0 {
+		switch unit {
+		case 'd':
+			return now.AddDate(0, 0, -n), nil
+		case 'w':
+			return now.AddDate(0, 0, -7*n), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("%q is not a date, timestamp or duration (e.g. 2024-05-01, 30d, 12h)", s)
+}
@@ -35,6 +35,9 @@ func runInit(cmd *cobra.Command, args []string) error {
 					huh.NewOption("Google Gemini (recommended)", llm.ProviderGemini),
 					huh.NewOption("OpenAI", llm.ProviderOpenAI),
 					huh.NewOption("Anthropic", llm.ProviderAnthropic),
+					huh.NewOption("Azure OpenAI", llm.ProviderAzureOpenAI),
+					huh.NewOption("AWS Bedrock", llm.ProviderBedrock),
+					huh.NewOption("Local / OpenAI-compatible (Ollama, vLLM, llama.cpp)", llm.ProviderLocal),
 				).
 				Value(&provider),
 		),
@@ -44,7 +47,7 @@ func runInit(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("setup cancelled: %w", err)
 	}
 
-	var apiKey, githubToken, model, outFmt string
+	var apiKey, baseURL, githubToken, model, outFmt string
 
 	apiKeyInput := huh.NewInput().
 		Title(llm.ProviderLabel(provider) + " API Key").
@@ -57,35 +60,53 @@ func runInit(cmd *cobra.Command, args []string) error {
 		apiKeyInput.Placeholder("sk-...")
 	case llm.ProviderAnthropic:
 		apiKeyInput.Placeholder("sk-ant-...")
-	default:
+	case llm.ProviderLocal:
+		apiKeyInput.Title("API Key (optional)").
+			Description("Only needed if your server requires authentication")
+	case llm.ProviderBedrock:
+		apiKeyInput.Title("Bedrock API Key (optional)").
+			Description("Leave empty to sign requests with AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	case llm.ProviderGemini:
 		apiKeyInput.Placeholder("AIza...")
 	}
 
-	modelSelect := buildModelSelect(provider, &model)
-
-	detailsForm := huh.NewForm(
-		huh.NewGroup(
-			apiKeyInput,
+	fields := []huh.Field{apiKeyInput}
+	switch provider {
+	case llm.ProviderLocal:
+		fields = append(fields, huh.NewInput().
+			Title("Base URL").
+			Description("OpenAI-compatible endpoint of your local server").
+			Placeholder(llm.LocalDefaultBaseURL).
+			Value(&baseURL))
+	case llm.ProviderAzureOpenAI:
+		fields = append(fields, huh.NewInput().
+			Title("Endpoint").
+			Description("Your Azure OpenAI resource endpoint").
+			Placeholder("https://my-resource.openai.azure.com").
+			Value(&baseURL))
+	}
 
-			huh.NewInput().
-				Title("GitHub Token (optional)").
-				Description("Required for scanning private repositories").
-				EchoMode(huh.EchoModePassword).
-				Value(&githubToken),
+	fields = append(fields,
+		huh.NewInput().
+			Title("GitHub Token (optional)").
+			Description("Required for scanning private repositories").
+			EchoMode(huh.EchoModePassword).
+			Value(&githubToken),
 
-			modelSelect,
+		buildModelField(provider, &model),
 
-			huh.NewSelect[string]().
-				Title("Default Output Format").
-				Options(
-					huh.NewOption("Table (human-friendly)", "table"),
-					huh.NewOption("JSON (CI/CD pipelines)", "json"),
-					huh.NewOption("Plain (minimal)", "plain"),
-				).
-				Value(&outFmt),
-		),
+		huh.NewSelect[string]().
+			Title("Default Output Format").
+			Options(
+				huh.NewOption("Table (human-friendly)", "table"),
+				huh.NewOption("JSON (CI/CD pipelines)", "json"),
+				huh.NewOption("Plain (minimal)", "plain"),
+			).
+			Value(&outFmt),
 	)
 
+	detailsForm := huh.NewForm(huh.NewGroup(fields...))
+
 	if err := detailsForm.Run(); err != nil {
 		return fmt.Errorf("setup cancelled: %w", err)
 	}
@@ -96,13 +117,15 @@ func runInit(cmd *cobra.Command, args []string) error {
 	cfg.DefaultModel = model
 	cfg.OutputFormat = outFmt
 
-	switch provider {
-	case llm.ProviderOpenAI:
-		cfg.OpenAIAPIKey = apiKey
-	case llm.ProviderAnthropic:
-		cfg.AnthropicAPIKey = apiKey
-	default:
-		cfg.GeminiAPIKey = apiKey
+	cfg.LLMBaseURL = baseURL
+	if err := cfg.SetActiveAPIKey(apiKey); err != nil {
+		return err
+	}
+	if cfg.LLMBaseURL == "" && provider == llm.ProviderLocal {
+		cfg.LLMBaseURL = llm.LocalDefaultBaseURL
+	}
+	if cfg.DefaultModel == "" {
+		cfg.DefaultModel = llm.DefaultModelForProvider(provider)
 	}
 
 	if err := cfg.Save(); err != nil {
@@ -131,8 +154,27 @@ func runInit(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func buildModelSelect(provider string, model *string) *huh.Select[string] {
+func buildModelField(provider string, model *string) huh.Field {
 	switch provider {
+	case llm.ProviderLocal:
+		// Local servers serve arbitrary models, so take a free-form name
+		return huh.NewInput().
+			Title("Model").
+			Description("Name of the model loaded on the server").
+			Placeholder(llm.LocalDefaultModel).
+			Value(model)
+	case llm.ProviderAzureOpenAI:
+		return huh.NewInput().
+			Title("Deployment").
+			Description("Name of the model deployment in your Azure resource").
+			Placeholder(llm.AzureOpenAIDefaultModel).
+			Value(model)
+	case llm.ProviderBedrock:
+		return huh.NewInput().
+			Title("Bedrock Model ID").
+			Description("Model ID or inference profile ID").
+			Placeholder(llm.BedrockDefaultModel).
+			Value(model)
 	case llm.ProviderOpenAI:
 		return huh.NewSelect[string]().
 			Title("Default OpenAI Model").
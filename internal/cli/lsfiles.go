@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/nerifect/nerifect-cli/internal/config"
+	"github.com/nerifect/nerifect-cli/internal/output"
+	"github.com/nerifect/nerifect-cli/internal/scanner"
+	"github.com/spf13/cobra"
+)
+
+func newLsFilesCmd() *cobra.Command {
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   "ls-files [path]",
+		Short: "List the files a scan would include",
+		Long: `List the files a scan of a local directory would include, applying the same
+rules as scan: skipped directories, binary files, max_file_size_kb,
+max_files_per_scan and .nerifectignore files (plus .gitignore files when
+respect_gitignore is set).
+
+With --all, excluded files and skipped directories are listed too, with the
+rule that excluded them, e.g. "ignored by src/.nerifectignore:3: *.log".
+The path defaults to the current directory; a configured repo name uses the
+repo's path.`,
+		Example: `  nerifect ls-files
+  nerifect ls-files ./service --all
+  nerifect ls-files my-repo --output json`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			target := "."
+			if len(args) > 0 {
+				target = args[0]
+			}
+			return runLsFiles(target, all)
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "also list excluded files and directories with the reason")
+	return cmd
+}
+
+func runLsFiles(target string, all bool) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	repo := cfg.FindRepo(target)
+	if repo != nil && repo.Path != "" && target == repo.Name {
+		target = repo.Path
+	}
+	if scanner.IsRemoteURL(target, cfg) {
+		return fmt.Errorf("ls-files only supports local directories")
+	}
+
+	root, err := filepath.Abs(target)
+	if err != nil {
+		return fmt.Errorf("resolving path %q: %w", target, err)
+	}
+	info, err := os.Stat(root)
+	if err != nil {
+		return fmt.Errorf("target path %q: %w", target, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("target %q is not a directory", target)
+	}
+
+	decisions, err := scanner.NewLocalFileReader(root, scanner.NewFileOptions(cfg, repo)).Explain()
+	if err != nil {
+		return fmt.Errorf("listing files: %w", err)
+	}
+	output.RenderFileList(root, decisions, all, output.ParseFormat(outputFormat))
+	return nil
+}
@@ -37,7 +37,8 @@ func newPolicyListCmd() *cobra.Command {
 }
 
 func newPolicyAddCmd() *cobra.Command {
-	return &cobra.Command{
+	var noCache bool
+	cmd := &cobra.Command{
 		Use:   "add <file-or-url>",
 		Short: "Add a policy from a file or URL",
 		Long: `Add a compliance policy by providing a URL to a regulation document
@@ -47,8 +48,12 @@ structured compliance rules.`,
   nerifect policy add /path/to/policy.txt
   nerifect policy add regulation.pdf`,
 		Args: cobra.ExactArgs(1),
-		RunE: runPolicyAdd,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPolicyAdd(cmd, args, noCache)
+		},
 	}
+	cmd.Flags().BoolVar(&noCache, "no-cache", false, "extract rules again instead of reusing cached LLM results")
+	return cmd
 }
 
 func newPolicyRemoveCmd() *cobra.Command {
@@ -79,7 +84,7 @@ func runPolicyList(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func runPolicyAdd(cmd *cobra.Command, args []string) error {
+func runPolicyAdd(cmd *cobra.Command, args []string, noCache bool) error {
 	cfg, err := config.Load()
 	if err != nil {
 		return err
@@ -92,7 +97,11 @@ func runPolicyAdd(cmd *cobra.Command, args []string) error {
 	}
 
 	source := args[0]
-	llmClient := llm.NewClient(cfg.LLMProvider, cfg.ActiveAPIKey(), cfg.DefaultModel)
+	llmOpts := cfg.LLMOptions()
+	if ttl := cfg.LLMCacheTTL(); ttl > 0 && !noCache {
+		llmOpts.Cache = store.NewLLMCache(ttl)
+	}
+	llmClient := llm.New(llmOpts)
 	mgr := policy.NewManager(llmClient)
 
 	outFmt := output.ParseFormat(outputFormat)
@@ -4,12 +4,16 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
+	"github.com/nerifect/nerifect-cli/internal/compliance"
 	"github.com/nerifect/nerifect-cli/internal/config"
 	"github.com/nerifect/nerifect-cli/internal/output"
 	"github.com/nerifect/nerifect-cli/internal/scanner"
+	"github.com/nerifect/nerifect-cli/internal/store"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 func newRepoCmd() *cobra.Command {
@@ -30,29 +34,37 @@ func newRepoAddCmd() *cobra.Command {
 		name     string
 		branch   string
 		scanType string
-		policies []int64
+		policies store.PolicySelection
+		gates    gateFlags
+		files    repoFileFlags
 	)
 
 	cmd := &cobra.Command{
 		Use:   "add <path-or-url>",
 		Short: "Add a repository to track",
-		Long: `Add a local directory or GitHub repository URL to the global config.
+		Long: `Add a local directory or git repository URL to the global config.
 The repo's branch, scan type, and policy filters will be used as defaults
 when scanning this target.`,
 		Example: `  nerifect repo add .
   nerifect repo add /path/to/project --name my-project --branch main
   nerifect repo add https://github.com/owner/repo --branch release/v2 --scan-type compliance
-  nerifect repo add . --policy 1 --policy 2`,
+  nerifect repo add . --policy 1 --policy 2
+  nerifect repo add . --policy gdpr --category SECURITY
+  nerifect repo add . --fail-on high --min-score 80
+  nerifect repo add . --include-dir vendor --include-dir build`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runRepoAdd(args[0], name, branch, scanType, policies)
+			return runRepoAdd(cmd, args[0], name, branch, scanType, policies, gates, files)
 		},
 	}
 
 	cmd.Flags().StringVar(&name, "name", "", "repo name (default: derived from path or URL)")
 	cmd.Flags().StringVar(&branch, "branch", "", "git branch or tag")
 	cmd.Flags().StringVar(&scanType, "scan-type", "", "scan type: full, compliance, ai")
-	cmd.Flags().Int64SliceVar(&policies, "policy", nil, "policy ID to apply (can repeat)")
+	cmd.Flags().StringSliceVar(&policies.Policies, "policy", nil, "policy ID, name, preset slug or regulation type to apply (can repeat)")
+	cmd.Flags().StringSliceVar(&policies.Categories, "category", nil, "only apply policies in this category (can repeat)")
+	addRepoGateFlags(cmd, &gates)
+	addRepoFileFlags(cmd, &files)
 	return cmd
 }
 
@@ -80,47 +92,132 @@ func newRepoUpdateCmd() *cobra.Command {
 	var (
 		branch   string
 		scanType string
-		policies []int64
+		policies store.PolicySelection
+		gates    gateFlags
+		files    repoFileFlags
 	)
 
 	cmd := &cobra.Command{
 		Use:   "update <name>",
 		Short: "Update a tracked repository's settings",
 		Example: `  nerifect repo update my-project --branch develop
-  nerifect repo update my-project --scan-type compliance --policy 1 --policy 2`,
+  nerifect repo update my-project --scan-type compliance --policy 1 --policy 2
+  nerifect repo update my-project --fail-on none --max-violations 0
+  nerifect repo update my-project --exclude-dir node_modules --exclude-dir testdata/large`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runRepoUpdate(args[0], branch, scanType, policies, cmd)
+			return runRepoUpdate(args[0], branch, scanType, policies, gates, files, cmd)
 		},
 	}
 
 	cmd.Flags().StringVar(&branch, "branch", "", "git branch or tag")
 	cmd.Flags().StringVar(&scanType, "scan-type", "", "scan type: full, compliance, ai")
-	cmd.Flags().Int64SliceVar(&policies, "policy", nil, "policy IDs to apply (replaces existing)")
+	cmd.Flags().StringSliceVar(&policies.Policies, "policy", nil, "policy IDs, names, preset slugs or regulation types to apply (replaces existing)")
+	cmd.Flags().StringSliceVar(&policies.Categories, "category", nil, "policy categories to apply (replaces existing)")
+	addRepoGateFlags(cmd, &gates)
+	addRepoFileFlags(cmd, &files)
 	return cmd
 }
 
-func runRepoAdd(target, name, branch, scanType string, policies []int64) error {
+// addRepoGateFlags registers the quality gate settings stored with a repo.
+func addRepoGateFlags(cmd *cobra.Command, gates *gateFlags) {
+	cmd.Flags().StringVar(&gates.failOn, "fail-on", "", "lowest violation severity that fails scans: critical, high, medium, low, none")
+	cmd.Flags().IntVar(&gates.minScore, "min-score", 0, "minimum compliance score for scans to pass (0 disables)")
+	cmd.Flags().IntVar(&gates.maxViolations, "max-violations", -1, "maximum violations for scans to pass (-1 disables)")
+}
+
+// validate checks the gate flags given on the command line.
+func (g gateFlags) validate(flags *pflag.FlagSet) error {
+	if flags.Changed("fail-on") {
+		if _, err := compliance.ParseFailOn(g.failOn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyTo stores the gate flags given on the command line in the repo config.
+func (g gateFlags) applyTo(flags *pflag.FlagSet, r *config.RepoConfig) {
+	if flags.Changed("fail-on") {
+		r.FailOn, _ = compliance.ParseFailOn(g.failOn)
+	}
+	if flags.Changed("min-score") {
+		r.MinScore = g.minScore
+	}
+	if flags.Changed("max-violations") {
+		r.MaxViolations = nil
+		if g.maxViolations >= 0 {
+			max := g.maxViolations
+			r.MaxViolations = &max
+		}
+	}
+}
+
+// repoFileFlags holds the file selection flags stored with a repo.
+type repoFileFlags struct {
+	excludeDirs       []string
+	includeDirs       []string
+	excludeExtensions []string
+	includeExtensions []string
+	maxFileSizeKB     int
+}
+
+// addRepoFileFlags registers the file selection settings stored with a repo.
+func addRepoFileFlags(cmd *cobra.Command, files *repoFileFlags) {
+	cmd.Flags().StringSliceVar(&files.excludeDirs, "exclude-dir", nil, "directory name or path to skip, replacing exclude_dirs (can repeat)")
+	cmd.Flags().StringSliceVar(&files.includeDirs, "include-dir", nil, "directory to scan even if it matches exclude_dirs (can repeat)")
+	cmd.Flags().StringSliceVar(&files.excludeExtensions, "exclude-ext", nil, "file extension to skip, replacing exclude_extensions (can repeat)")
+	cmd.Flags().StringSliceVar(&files.includeExtensions, "include-ext", nil, "only scan files with this extension (can repeat)")
+	cmd.Flags().IntVar(&files.maxFileSizeKB, "max-file-size-kb", 0, "maximum file size in KB (0 uses max_file_size_kb)")
+}
+
+// applyTo stores the file selection flags given on the command line in the
+// repo config.
+func (f repoFileFlags) applyTo(flags *pflag.FlagSet, r *config.RepoConfig) {
+	if flags.Changed("exclude-dir") {
+		r.ExcludeDirs = f.excludeDirs
+	}
+	if flags.Changed("include-dir") {
+		r.IncludeDirs = f.includeDirs
+	}
+	if flags.Changed("exclude-ext") {
+		r.ExcludeExtensions = f.excludeExtensions
+	}
+	if flags.Changed("include-ext") {
+		r.IncludeExtensions = f.includeExtensions
+	}
+	if flags.Changed("max-file-size-kb") {
+		r.MaxFileSizeKB = f.maxFileSizeKB
+	}
+}
+
+func runRepoAdd(cmd *cobra.Command, target, name, branch, scanType string, policies store.PolicySelection, gates gateFlags, files repoFileFlags) error {
 	cfg, err := config.Load()
 	if err != nil {
 		return err
 	}
 
 	repo := config.RepoConfig{
-		Branch:   branch,
-		ScanType: scanType,
-		Policies: policies,
+		Branch:     branch,
+		ScanType:   scanType,
+		Policies:   policies.Policies,
+		Categories: policies.Categories,
 	}
+	if err := gates.validate(cmd.Flags()); err != nil {
+		return err
+	}
+	gates.applyTo(cmd.Flags(), &repo)
+	files.applyTo(cmd.Flags(), &repo)
 
 	// Determine if target is a URL or local path
-	if scanner.IsGitHubURL(target) {
+	if scanner.IsRemoteURL(target, cfg) {
+		remote, err := scanner.ParseRemote(target, cfg)
+		if err != nil {
+			return err
+		}
 		repo.URL = target
 		if name == "" {
-			owner, repoName, err := scanner.ParseGitHubURL(target)
-			if err != nil {
-				return fmt.Errorf("cannot parse URL for name: %w", err)
-			}
-			name = owner + "/" + repoName
+			name = remote.Name
 		}
 	} else {
 		// Local path - resolve to absolute
@@ -190,10 +287,22 @@ func runRepoList(cmd *cobra.Command, args []string) error {
 		if len(r.Policies) > 0 {
 			policyStrs := make([]string, len(r.Policies))
 			for i, p := range r.Policies {
-				policyStrs[i] = fmt.Sprintf("#%d", p)
+				policyStrs[i] = p
+				if _, err := strconv.ParseInt(p, 10, 64); err == nil {
+					policyStrs[i] = "#" + p
+				}
 			}
 			fmt.Printf("    Policies:  %s\n", strings.Join(policyStrs, ", "))
 		}
+		if len(r.Categories) > 0 {
+			fmt.Printf("    Category:  %s\n", strings.Join(r.Categories, ", "))
+		}
+		if gates := repoGateSummary(r); gates != "" {
+			fmt.Printf("    Gates:     %s\n", gates)
+		}
+		if files := repoFileSummary(r); files != "" {
+			fmt.Printf("    Files:     %s\n", files)
+		}
 		fmt.Println()
 	}
 
@@ -201,6 +310,42 @@ func runRepoList(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// repoGateSummary describes the quality gates configured for a repo.
+func repoGateSummary(r config.RepoConfig) string {
+	var parts []string
+	if r.FailOn != "" {
+		parts = append(parts, "fail-on "+r.FailOn)
+	}
+	if r.MinScore > 0 {
+		parts = append(parts, fmt.Sprintf("min-score %d", r.MinScore))
+	}
+	if r.MaxViolations != nil {
+		parts = append(parts, fmt.Sprintf("max-violations %d", *r.MaxViolations))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// repoFileSummary describes the file selection configured for a repo.
+func repoFileSummary(r config.RepoConfig) string {
+	var parts []string
+	if r.ExcludeDirs != nil {
+		parts = append(parts, "exclude dirs "+strings.Join(r.ExcludeDirs, ","))
+	}
+	if r.IncludeDirs != nil {
+		parts = append(parts, "include dirs "+strings.Join(r.IncludeDirs, ","))
+	}
+	if r.ExcludeExtensions != nil {
+		parts = append(parts, "exclude ext "+strings.Join(r.ExcludeExtensions, ","))
+	}
+	if r.IncludeExtensions != nil {
+		parts = append(parts, "include ext "+strings.Join(r.IncludeExtensions, ","))
+	}
+	if r.MaxFileSizeKB > 0 {
+		parts = append(parts, fmt.Sprintf("max %d KB", r.MaxFileSizeKB))
+	}
+	return strings.Join(parts, "; ")
+}
+
 func runRepoRemove(name string) error {
 	cfg, err := config.Load()
 	if err != nil {
@@ -215,12 +360,16 @@ func runRepoRemove(name string) error {
 	return nil
 }
 
-func runRepoUpdate(name, branch, scanType string, policies []int64, cmd *cobra.Command) error {
+func runRepoUpdate(name, branch, scanType string, policies store.PolicySelection, gates gateFlags, files repoFileFlags, cmd *cobra.Command) error {
 	cfg, err := config.Load()
 	if err != nil {
 		return err
 	}
 
+	if err := gates.validate(cmd.Flags()); err != nil {
+		return err
+	}
+
 	err = cfg.UpdateRepo(name, func(r *config.RepoConfig) {
 		if cmd.Flags().Changed("branch") {
 			r.Branch = branch
@@ -229,8 +378,13 @@ func runRepoUpdate(name, branch, scanType string, policies []int64, cmd *cobra.C
 			r.ScanType = scanType
 		}
 		if cmd.Flags().Changed("policy") {
-			r.Policies = policies
+			r.Policies = policies.Policies
+		}
+		if cmd.Flags().Changed("category") {
+			r.Categories = policies.Categories
 		}
+		gates.applyTo(cmd.Flags(), r)
+		files.applyTo(cmd.Flags(), r)
 	})
 	if err != nil {
 		return err
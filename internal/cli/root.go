@@ -39,14 +39,20 @@ and generates AI-powered fixes using Google Gemini.`,
 	}
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default ~/.nerifect.yaml)")
-	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "table", "output format: table, json, plain, sarif")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "table", "output format: table, json, plain, sarif, markdown (diff only)")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
 
 	rootCmd.AddCommand(newInitCmd())
 	rootCmd.AddCommand(newScanCmd())
+	rootCmd.AddCommand(newLsFilesCmd())
 	rootCmd.AddCommand(newPolicyCmd())
 	rootCmd.AddCommand(newFixCmd())
 	rootCmd.AddCommand(newReportCmd())
+	rootCmd.AddCommand(newBaselineCmd())
+	rootCmd.AddCommand(newDiffCmd())
+	rootCmd.AddCommand(newHistoryCmd())
+	rootCmd.AddCommand(newUsageCmd())
+	rootCmd.AddCommand(newCacheCmd())
 	rootCmd.AddCommand(newConfigCmd())
 	rootCmd.AddCommand(newRepoCmd())
 	rootCmd.AddCommand(newAgentCmd())
@@ -2,44 +2,133 @@ package cli
 
 import (
 	"fmt"
-	"os"
 	"strings"
 
+	"github.com/nerifect/nerifect-cli/internal/compliance"
 	"github.com/nerifect/nerifect-cli/internal/config"
 	"github.com/nerifect/nerifect-cli/internal/output"
 	"github.com/nerifect/nerifect-cli/internal/scanner"
 	"github.com/nerifect/nerifect-cli/internal/store"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 func newScanCmd() *cobra.Command {
 	var scanTypeFlag string
 	var diffBase string
+	var baselineFile string
+	var noCache bool
+	var history bool
+	var since string
+	var policies store.PolicySelection
+	var gates gateFlags
 
 	cmd := &cobra.Command{
 		Use:   "scan <path-or-url>",
 		Short: "Scan a repository or directory",
-		Long: `Scan a local directory or GitHub repository for compliance violations
-and AI governance issues.`,
+		Long: `Scan a local directory or remote git repository for compliance violations
+and AI governance issues. Remote repositories can be on GitHub (including
+GitHub Enterprise hosts in github_hosts), GitLab, Bitbucket or any https:// or
+ssh:// git URL.
+
+With --history, the lines added by each commit of the repository's git
+history are checked against pattern rules instead of the current files, to
+find secrets and other violations that were committed and later removed.
+Findings are tagged with the commit that added them, and each file version is
+checked only once. --since limits the scan to commits after a git ref.`,
 		Example: `  nerifect scan .
   nerifect scan /path/to/project
   nerifect scan https://github.com/owner/repo
+  nerifect scan https://gitlab.example.com/group/project
+  nerifect scan ssh://git@git.example.com/team/repo.git
   nerifect scan --type ai .
   nerifect scan --type compliance . --output json
   nerifect scan --diff .
-  nerifect scan --diff main .`,
+  nerifect scan --diff main .
+  nerifect scan --history .
+  nerifect scan --history --since v1.2.0 .
+  nerifect scan . --baseline .nerifect-baseline.json
+  nerifect scan . --policy gdpr --category SECURITY
+  nerifect scan . --fail-on high --min-score 80 --max-violations 10`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runScan(cmd, args[0], scanTypeFlag, diffBase)
+			return runScan(cmd, args[0], scanTypeFlag, diffBase, baselineFile, noCache, history, since, policies, gates)
 		},
 	}
 
 	cmd.Flags().StringVar(&scanTypeFlag, "type", "full", "scan type: full, compliance, ai")
 	cmd.Flags().StringVar(&diffBase, "diff", "", "scan only changed files (vs git ref, default HEAD if flag set without value)")
+	cmd.Flags().BoolVar(&history, "history", false, "scan the lines added by each commit of the git history with pattern rules")
+	cmd.Flags().StringVar(&since, "since", "", "with --history, only scan commits after this git ref")
+	cmd.Flags().StringVar(&baselineFile, "baseline", "", "baseline file; violations it lists are reported as baselined and do not fail the scan")
+	cmd.Flags().BoolVar(&noCache, "no-cache", false, "re-evaluate all files instead of reusing cached LLM results")
+	cmd.Flags().StringSliceVar(&policies.Policies, "policy", nil, "policy ID, name, preset slug or regulation type to scan with (can repeat)")
+	cmd.Flags().StringSliceVar(&policies.Categories, "category", nil, "only scan with policies in this category, e.g. SECURITY (can repeat)")
+	cmd.Flags().StringVar(&gates.failOn, "fail-on", "critical", "exit 2 if violations of this severity or above are found: critical, high, medium, low, none")
+	cmd.Flags().IntVar(&gates.minScore, "min-score", 0, "exit 2 if the compliance score is below this value (0 disables)")
+	cmd.Flags().IntVar(&gates.maxViolations, "max-violations", -1, "exit 2 if more violations than this are found (-1 disables)")
+	cmd.Flags().BoolVar(&gates.allowDegraded, "allow-degraded", false, "do not exit 2 when the LLM evaluation could not be completed")
 	return cmd
 }
 
-func runScan(cmd *cobra.Command, target, scanTypeStr, diffBase string) error {
+// gateFlags holds the quality gate flags of the scan command.
+type gateFlags struct {
+	failOn        string
+	minScore      int
+	maxViolations int
+	allowDegraded bool
+}
+
+// resolve combines the gate flags with the matched repo's settings. Flags
+// given on the command line win over the repo config, which wins over the
+// defaults.
+func (g gateFlags) resolve(flags *pflag.FlagSet, repo *config.RepoConfig) (compliance.GateOptions, error) {
+	opts := compliance.DefaultGateOptions()
+	if repo != nil {
+		if repo.FailOn != "" {
+			opts.FailOn = repo.FailOn
+		}
+		if repo.MinScore > 0 {
+			opts.MinScore = repo.MinScore
+		}
+		if repo.MaxViolations != nil {
+			opts.MaxViolations = *repo.MaxViolations
+		}
+	}
+	if flags.Changed("fail-on") {
+		opts.FailOn = g.failOn
+	}
+	if flags.Changed("min-score") {
+		opts.MinScore = g.minScore
+	}
+	if flags.Changed("max-violations") {
+		opts.MaxViolations = g.maxViolations
+	}
+	opts.AllowDegraded = g.allowDegraded
+
+	failOn, err := compliance.ParseFailOn(opts.FailOn)
+	if err != nil {
+		return opts, err
+	}
+	opts.FailOn = failOn
+	return opts, nil
+}
+
+func runScan(cmd *cobra.Command, target, scanTypeStr, diffBase, baselineFile string, noCache, history bool, since string, policies store.PolicySelection, gates gateFlags) error {
+	if history {
+		if cmd.Flags().Changed("type") {
+			return fmt.Errorf("--history cannot be combined with --type")
+		}
+		if cmd.Flags().Changed("diff") {
+			return fmt.Errorf("--history cannot be combined with --diff")
+		}
+		if cmd.Flags().Changed("since") && strings.TrimSpace(since) == "" {
+			return fmt.Errorf("--since requires a git ref")
+		}
+	} else if cmd.Flags().Changed("since") {
+		return fmt.Errorf("--since requires --history")
+	}
+
 	cfg, err := config.Load()
 	if err != nil {
 		return fmt.Errorf("loading config: %w", err)
@@ -52,22 +141,31 @@ func runScan(cmd *cobra.Command, target, scanTypeStr, diffBase string) error {
 
 	// Check if target matches a configured repo
 	var branch string
-	var policyIDs []int64
-	if repo := cfg.FindRepo(target); repo != nil {
+	var selection store.PolicySelection
+	repo := cfg.FindRepo(target)
+	if repo != nil {
 		// Use repo URL/path as target if matched by name
-		if repo.URL != "" && !scanner.IsGitHubURL(target) && target == repo.Name {
+		if repo.URL != "" && !scanner.IsRemoteURL(target, cfg) && target == repo.Name {
 			target = repo.URL
 		} else if repo.Path != "" && target == repo.Name {
 			target = repo.Path
 		}
 		branch = repo.Branch
-		policyIDs = repo.Policies
+		selection = store.PolicySelection{Policies: repo.Policies, Categories: repo.Categories}
 		// Use repo's scan type as default if not explicitly set via flag
 		if !cmd.Flags().Changed("type") && repo.ScanType != "" {
 			scanTypeStr = repo.ScanType
 		}
 	}
 
+	// Policy flags replace the repo's selection
+	if cmd.Flags().Changed("policy") {
+		selection.Policies = policies.Policies
+	}
+	if cmd.Flags().Changed("category") {
+		selection.Categories = policies.Categories
+	}
+
 	// Parse scan type
 	var scanType store.ScanType
 	switch strings.ToLower(scanTypeStr) {
@@ -78,18 +176,38 @@ func runScan(cmd *cobra.Command, target, scanTypeStr, diffBase string) error {
 	default:
 		scanType = store.ScanTypeFull
 	}
+	if history {
+		scanType = store.ScanTypeHistory
+	}
+
+	gateOpts, err := gates.resolve(cmd.Flags(), repo)
+	if err != nil {
+		return err
+	}
+
+	var baseline *compliance.Baseline
+	if baselineFile != "" {
+		baseline, err = compliance.LoadBaseline(baselineFile)
+		if err != nil {
+			return fmt.Errorf("loading baseline: %w", err)
+		}
+	}
 
 	outFmt := output.ParseFormat(outputFormat)
 
 	// Start scanning with progress
 	var progress *output.Progress
 	if outFmt != output.FormatJSON && outFmt != output.FormatSARIF {
-		progress = output.NewProgress("Scanning " + target + "...")
+		progress = output.NewProgress("Scanning " + scanner.RedactURL(target) + "...")
 	}
 
 	opts := scanner.ScanOptions{
-		Branch:    branch,
-		PolicyIDs: policyIDs,
+		Branch:   branch,
+		Policies: selection,
+		Baseline: baseline,
+		NoCache:  noCache,
+		Repo:     repo,
+		Since:    since,
 	}
 
 	// Handle --diff flag: if flag was changed but value is empty, default to HEAD
@@ -114,11 +232,10 @@ func runScan(cmd *cobra.Command, target, scanTypeStr, diffBase string) error {
 	// Render results
 	output.RenderScanReport(result.Scan, result.Violations, result.Detections, outFmt)
 
-	// Exit code 2 for critical violations (CI/CD gate)
-	for _, v := range result.Violations {
-		if strings.ToUpper(string(v.Severity)) == "CRITICAL" {
-			os.Exit(2)
-		}
+	// Exit code 2 when a quality gate fails (CI/CD gate)
+	if failures := compliance.EvaluateGates(gateOpts, result.Scan, result.Violations); len(failures) > 0 {
+		output.PrintGateFailures(failures)
+		return &ExitError{Code: 2}
 	}
 
 	return nil
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nerifect/nerifect-cli/internal/config"
+	"github.com/nerifect/nerifect-cli/internal/llm"
+	"github.com/nerifect/nerifect-cli/internal/output"
+	"github.com/nerifect/nerifect-cli/internal/scanner"
+	"github.com/nerifect/nerifect-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+func newUsageCmd() *cobra.Command {
+	var since string
+
+	cmd := &cobra.Command{
+		Use:   "usage",
+		Short: "Show LLM token usage and estimated cost",
+		Long: `Total the tokens used by LLM calls for scans, policy ingestion and fixes,
+grouped by provider, model and operation, with an estimated cost.
+
+Costs are estimates from built-in list prices per million tokens. Override or
+add prices with llm_prices in ~/.nerifect.yaml. --since accepts a date
+(2024-05-01), an RFC 3339 timestamp or a relative duration such as 30d, 2w
+or 12h.`,
+		Example: `  nerifect usage
+  nerifect usage --since 7d
+  nerifect usage --since 2024-05-01 --output json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runUsage(since)
+		},
+	}
+
+	cmd.Flags().StringVar(&since, "since", "", "only include calls made at or after this time")
+	return cmd
+}
+
+func runUsage(sinceStr string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	if _, err := store.Open(cfg.DatabasePath); err != nil {
+		return fmt.Errorf("initializing database: %w", err)
+	}
+
+	var filter store.UsageFilter
+	if filter.Since, err = parseTimeFlag(sinceStr, time.Now()); err != nil {
+		return fmt.Errorf("invalid --since: %w", err)
+	}
+
+	summaries, err := store.SummarizeLLMUsage(filter)
+	if err != nil {
+		return fmt.Errorf("loading usage: %w", err)
+	}
+
+	entries := make([]output.UsageEntry, len(summaries))
+	for i, s := range summaries {
+		cost, ok := cfg.EstimateCost(s.Provider, s.Model, llm.Usage{InputTokens: s.InputTokens, OutputTokens: s.OutputTokens})
+		entries[i] = output.UsageEntry{UsageSummary: s, EstimatedCost: cost, Priced: ok}
+	}
+	output.RenderUsage(filter.Since, entries, scanner.TotalUsage(cfg, summaries), output.ParseFormat(outputFormat))
+	return nil
+}
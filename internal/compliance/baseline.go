@@ -0,0 +1,120 @@
+package compliance
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/nerifect/nerifect-cli/internal/store"
+)
+
+// DefaultBaselineFile is where `nerifect baseline create` writes by default.
+const DefaultBaselineFile = ".nerifect-baseline.json"
+
+const baselineVersion = 1
+
+// Baseline records the fingerprints of accepted, pre-existing violations so
+// that later scans only report findings that are new.
+type Baseline struct {
+	Version   int             `json:"version"`
+	CreatedAt time.Time       `json:"created_at"`
+	ScanID    int64           `json:"scan_id"`
+	Target    string          `json:"target"`
+	Entries   []BaselineEntry `json:"entries"`
+
+	remaining map[string]int
+}
+
+// BaselineEntry is a single baselined violation. Only the fingerprint is used
+// for matching; the other fields make the file reviewable. Identical findings
+// get one entry each, so a new copy of a baselined finding is still reported.
+type BaselineEntry struct {
+	Fingerprint string `json:"fingerprint"`
+	RuleID      string `json:"rule_id"`
+	Severity    string `json:"severity"`
+	FilePath    string `json:"file_path"`
+	Title       string `json:"title"`
+}
+
+// NewBaseline builds a baseline from the violations of a scan. Suppressed
+// violations are left out because their inline comments already cover them,
+// and unverified ones because they do not count toward the score.
+func NewBaseline(scan *store.Scan, violations []store.Violation) *Baseline {
+	b := &Baseline{
+		Version:   baselineVersion,
+		CreatedAt: time.Now().UTC(),
+		ScanID:    scan.ID,
+		Target:    scan.Target,
+		Entries:   []BaselineEntry{},
+	}
+	for _, v := range violations {
+		if v.Suppressed || v.Unverified {
+			continue
+		}
+		b.Entries = append(b.Entries, BaselineEntry{
+			Fingerprint: violationFingerprint(v),
+			RuleID:      v.RuleID,
+			Severity:    string(v.Severity),
+			FilePath:    v.FilePath,
+			Title:       v.Title,
+		})
+	}
+	return b
+}
+
+// LoadBaseline reads a baseline file written by Save.
+func LoadBaseline(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var b Baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("parsing baseline %s: %w", path, err)
+	}
+	if b.Version > baselineVersion {
+		return nil, fmt.Errorf("baseline %s has unsupported version %d", path, b.Version)
+	}
+	return &b, nil
+}
+
+// Save writes the baseline as indented JSON.
+func (b *Baseline) Save(path string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling baseline: %w", err)
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// claim reports whether the baseline has an unused entry for the fingerprint
+// and uses it up.
+func (b *Baseline) claim(fingerprint string) bool {
+	if b.remaining == nil {
+		b.remaining = make(map[string]int, len(b.Entries))
+		for _, e := range b.Entries {
+			b.remaining[e.Fingerprint]++
+		}
+	}
+	if b.remaining[fingerprint] == 0 {
+		return false
+	}
+	b.remaining[fingerprint]--
+	return true
+}
+
+// ApplyBaseline marks the violations matching an entry of the baseline. Each
+// entry matches at most one violation. A nil baseline leaves the violations
+// unchanged.
+func ApplyBaseline(violations []ViolationResult, b *Baseline) []ViolationResult {
+	if b == nil {
+		return violations
+	}
+	for i, v := range violations {
+		if b.claim(Fingerprint(v.RuleID, v.FilePath, v.CodeSnippet)) {
+			violations[i].Baselined = true
+		}
+	}
+	return violations
+}
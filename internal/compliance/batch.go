@@ -0,0 +1,159 @@
+package compliance
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"sort"
+	"strings"
+
+	"github.com/nerifect/nerifect-cli/internal/llm"
+)
+
+// evaluationBatch is the rules and files sent in one evaluation prompt.
+type evaluationBatch struct {
+	rules []llm.ComplianceRule
+	files []string
+	// fileBudget is the most tokens a single file may take up; larger files
+	// are truncated to it.
+	fileBudget int
+}
+
+// batchPlan splits every rule and file into batches whose prompts fit a
+// token budget. Each file is evaluated against every rule exactly once.
+type batchPlan struct {
+	batches []evaluationBatch
+	// truncated lists files too large for a batch on their own; only their
+	// beginning is evaluated.
+	truncated []string
+}
+
+// boundaryFileTokens is the size of a typical source file in tokens. Path
+// hashes end a batch on average after as many files as the budget fits at
+// that size, so most batches end at a boundary rather than at the budget.
+const boundaryFileTokens = 1500
+
+// planBatches groups rules into sets of at most a quarter of the budget and
+// packs the files, in path order, into the remaining space for each rule set.
+// tokens holds the prompt tokens of each file.
+//
+// Batches are cached by their contents, so besides the budget, a batch also
+// ends after any path whose hash marks a boundary. Adding, removing or
+// resizing a file then only changes the batches up to the next boundary
+// instead of shifting every later batch, and the rest are still cached.
+func planBatches(rules []llm.ComplianceRule, tokens map[string]int, budget int) batchPlan {
+	var plan batchPlan
+	if len(rules) == 0 || len(tokens) == 0 {
+		return plan
+	}
+
+	overhead := llm.EstimateTokens(llm.BuildCompliancePrompt(nil, nil))
+	paths := make([]string, 0, len(tokens))
+	for path := range tokens {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	truncated := make(map[string]bool)
+	for _, group := range groupRules(rules, budget/4) {
+		fileBudget := budget - overhead - jsonTokens(group)
+		if fileBudget < budget/4 {
+			fileBudget = budget / 4
+		}
+		every := uint32(fileBudget / boundaryFileTokens)
+		if every < 1 {
+			every = 1
+		}
+
+		var batch []string
+		used := 0
+		for _, path := range paths {
+			t := tokens[path]
+			if t > fileBudget {
+				t = fileBudget
+				truncated[path] = true
+			}
+			if used+t > fileBudget && len(batch) > 0 {
+				plan.batches = append(plan.batches, evaluationBatch{rules: group, files: batch, fileBudget: fileBudget})
+				batch, used = nil, 0
+			}
+			batch = append(batch, path)
+			used += t
+			if isBoundary(path, every) {
+				plan.batches = append(plan.batches, evaluationBatch{rules: group, files: batch, fileBudget: fileBudget})
+				batch, used = nil, 0
+			}
+		}
+		if len(batch) > 0 {
+			plan.batches = append(plan.batches, evaluationBatch{rules: group, files: batch, fileBudget: fileBudget})
+		}
+	}
+
+	for path := range truncated {
+		plan.truncated = append(plan.truncated, path)
+	}
+	sort.Strings(plan.truncated)
+	return plan
+}
+
+// isBoundary reports whether path ends a batch, which on average one path in
+// every does.
+func isBoundary(path string, every uint32) bool {
+	h := fnv.New32a()
+	h.Write([]byte(path))
+	return h.Sum32()%every == 0
+}
+
+// promptFile prepares a file for the evaluation prompt, truncating it to
+// budget tokens.
+func promptFile(path, content string, budget int) llm.PromptFile {
+	f := llm.PromptFile{Path: path, Content: llm.NumberLines(content)}
+	tokens := jsonTokens(f)
+	for tokens > budget && f.Content != "" {
+		f.Content = truncateContent(f.Content, tokens-budget)
+		tokens = jsonTokens(f)
+	}
+	return f
+}
+
+// groupRules splits rules into consecutive groups of at most budget tokens.
+// A rule larger than the budget gets a group of its own.
+func groupRules(rules []llm.ComplianceRule, budget int) [][]llm.ComplianceRule {
+	var groups [][]llm.ComplianceRule
+	var group []llm.ComplianceRule
+	used := 0
+	for _, r := range rules {
+		tokens := jsonTokens(r)
+		if used+tokens > budget && len(group) > 0 {
+			groups = append(groups, group)
+			group, used = nil, 0
+		}
+		group = append(group, r)
+		used += tokens
+	}
+	if len(group) > 0 {
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// jsonTokens estimates the tokens v takes up in the prompt's indented JSON.
+func jsonTokens(v interface{}) int {
+	data, _ := json.MarshalIndent(v, "    ", "  ")
+	return llm.EstimateTokens(string(data))
+}
+
+// truncateContent removes roughly excess tokens from the end of content,
+// cutting at a line boundary where possible.
+func truncateContent(content string, excess int) string {
+	// JSON escaping makes content longer than its raw length, so cut a
+	// little more than the estimate
+	keep := len(content) - excess*4 - excess/2
+	if keep <= 0 {
+		return ""
+	}
+	cut := content[:keep]
+	if i := strings.LastIndexByte(cut, '\n'); i > keep/2 {
+		cut = cut[:i+1]
+	}
+	return strings.ToValidUTF8(cut, "")
+}
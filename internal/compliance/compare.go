@@ -0,0 +1,109 @@
+package compliance
+
+import "github.com/nerifect/nerifect-cli/internal/store"
+
+// ScanSnapshot is a stored scan together with its findings.
+type ScanSnapshot struct {
+	Scan       *store.Scan
+	Violations []store.Violation
+	Detections []store.AIDetection
+}
+
+// ScanDiff describes how the findings changed from a base scan to a head scan.
+type ScanDiff struct {
+	Base *store.Scan `json:"base"`
+	Head *store.Scan `json:"head"`
+	// ScoreDelta is the head score minus the base score, or nil when either
+	// scan has no score.
+	ScoreDelta        *int                `json:"score_delta"`
+	New               []store.Violation   `json:"new"`
+	Fixed             []store.Violation   `json:"fixed"`
+	Unchanged         []store.Violation   `json:"unchanged"`
+	AddedDetections   []store.AIDetection `json:"added_detections"`
+	RemovedDetections []store.AIDetection `json:"removed_detections"`
+}
+
+// CompareScans matches the violations of two scans by fingerprint and the AI
+// detections by name and file. Suppressed violations are left out on both
+// sides. Identical findings are matched one to one, so an extra copy of an
+// existing finding shows up as new.
+func CompareScans(base, head ScanSnapshot) *ScanDiff {
+	d := &ScanDiff{
+		Base:              base.Scan,
+		Head:              head.Scan,
+		New:               []store.Violation{},
+		Fixed:             []store.Violation{},
+		Unchanged:         []store.Violation{},
+		AddedDetections:   []store.AIDetection{},
+		RemovedDetections: []store.AIDetection{},
+	}
+	if base.Scan.ComplianceScore != nil && head.Scan.ComplianceScore != nil {
+		delta := *head.Scan.ComplianceScore - *base.Scan.ComplianceScore
+		d.ScoreDelta = &delta
+	}
+
+	remaining := make(map[string][]store.Violation)
+	for _, v := range base.Violations {
+		if v.Suppressed {
+			continue
+		}
+		fp := violationFingerprint(v)
+		remaining[fp] = append(remaining[fp], v)
+	}
+	for _, v := range head.Violations {
+		if v.Suppressed {
+			continue
+		}
+		fp := violationFingerprint(v)
+		if len(remaining[fp]) > 0 {
+			remaining[fp] = remaining[fp][1:]
+			d.Unchanged = append(d.Unchanged, v)
+		} else {
+			d.New = append(d.New, v)
+		}
+	}
+	// Keep the base scan's order for fixed findings
+	for _, v := range base.Violations {
+		if v.Suppressed {
+			continue
+		}
+		fp := violationFingerprint(v)
+		if len(remaining[fp]) > 0 && remaining[fp][0].ID == v.ID {
+			remaining[fp] = remaining[fp][1:]
+			d.Fixed = append(d.Fixed, v)
+		}
+	}
+
+	baseDetections := make(map[string]int)
+	for _, det := range base.Detections {
+		baseDetections[detectionKey(det)]++
+	}
+	headDetections := make(map[string]int)
+	for _, det := range head.Detections {
+		key := detectionKey(det)
+		headDetections[key]++
+		if headDetections[key] > baseDetections[key] {
+			d.AddedDetections = append(d.AddedDetections, det)
+		}
+	}
+	seen := make(map[string]int)
+	for _, det := range base.Detections {
+		key := detectionKey(det)
+		seen[key]++
+		if seen[key] > headDetections[key] {
+			d.RemovedDetections = append(d.RemovedDetections, det)
+		}
+	}
+	return d
+}
+
+func violationFingerprint(v store.Violation) string {
+	if v.Fingerprint != "" {
+		return v.Fingerprint
+	}
+	return Fingerprint(v.RuleID, v.FilePath, v.CodeSnippet)
+}
+
+func detectionKey(d store.AIDetection) string {
+	return d.Name + "|" + d.FilePath
+}
@@ -2,23 +2,41 @@ package compliance
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
+	"sync"
 
 	"github.com/nerifect/nerifect-cli/internal/llm"
+	"github.com/nerifect/nerifect-cli/internal/store"
+)
+
+// Defaults for NewEvaluator when the budget or worker count is not set.
+const (
+	DefaultBatchTokens = 30000
+	DefaultWorkers     = 4
 )
 
 // Evaluator performs LLM-powered compliance evaluation.
 type Evaluator struct {
-	client          *llm.Client
-	maxFilesPerBatch int
-	maxCharsPerFile  int
+	client      *llm.Client
+	batchTokens int
+	workers     int
 }
 
-func NewEvaluator(client *llm.Client) *Evaluator {
+// NewEvaluator creates an evaluator that sends prompts of at most
+// batchTokens input tokens, with up to workers prompts in flight.
+func NewEvaluator(client *llm.Client, batchTokens, workers int) *Evaluator {
+	if batchTokens <= 0 {
+		batchTokens = DefaultBatchTokens
+	}
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
 	return &Evaluator{
-		client:          client,
-		maxFilesPerBatch: 15,
-		maxCharsPerFile:  4000,
+		client:      client,
+		batchTokens: batchTokens,
+		workers:     workers,
 	}
 }
 
@@ -26,65 +44,212 @@ func NewEvaluator(client *llm.Client) *Evaluator {
 type EvaluationResult struct {
 	Violations      []ViolationResult
 	ComplianceScore int
-	FilesScanned    int
-	RulesEvaluated  int
+	Coverage        Coverage
+	// TruncatedFiles were too large for a single prompt and only partly
+	// evaluated.
+	TruncatedFiles []string
+}
+
+// Coverage counts the files and rules that were evaluated. A file counts
+// only if it was evaluated in full against every rule, and a rule only if
+// every file was evaluated against it. FilesPartial were too large for a
+// prompt and only their beginning was evaluated; they are not counted in
+// FilesEvaluated.
+type Coverage struct {
+	FilesEvaluated int
+	FilesTotal     int
+	RulesEvaluated int
+	RulesTotal     int
+	FilesPartial   int
+}
+
+// FileSource reads the files being evaluated.
+type FileSource interface {
+	ReadFile(path string) (string, error)
 }
 
 // Evaluate runs LLM-based compliance evaluation of files against policies.
-func (e *Evaluator) Evaluate(ctx context.Context, policies []map[string]interface{}, files map[string]string) (*EvaluationResult, error) {
-	if len(policies) == 0 {
-		return &EvaluationResult{
-			ComplianceScore: 100,
-			FilesScanned:    len(files),
-		}, nil
+// Files and rules are split into batches that fit the token budget and
+// evaluated concurrently. Files are read from source as each batch is sent,
+// so only the files of the batches in flight are held in memory. Violations
+// whose code snippet is not found in the file are marked unverified.
+func (e *Evaluator) Evaluate(ctx context.Context, policies []store.Policy, paths []string, source FileSource) (*EvaluationResult, error) {
+	rules := flattenRules(policies)
+	result := &EvaluationResult{
+		ComplianceScore: 100,
+		Coverage:        Coverage{FilesTotal: len(paths), RulesTotal: len(rules)},
+	}
+	if len(rules) == 0 || len(paths) == 0 {
+		return result, nil
 	}
 
-	prompt := llm.BuildCompliancePrompt(policies, files, e.maxFilesPerBatch, e.maxCharsPerFile)
+	// Size every file up front; unreadable files are not evaluated
+	failedFiles := make(map[string]bool)
+	tokens := make(map[string]int, len(paths))
+	for _, path := range paths {
+		content, err := source.ReadFile(path)
+		if err != nil {
+			failedFiles[path] = true
+			continue
+		}
+		tokens[path] = jsonTokens(llm.PromptFile{Path: path, Content: llm.NumberLines(content)})
+	}
 
-	responseText, err := e.client.GenerateContent(ctx, prompt)
-	if err != nil {
-		return nil, fmt.Errorf("LLM evaluation failed: %w", err)
+	plan := planBatches(rules, tokens, e.batchTokens)
+	result.TruncatedFiles = plan.truncated
+
+	responses := make([]evaluationResponse, len(plan.batches))
+	errs := make([]error, len(plan.batches))
+	sem := make(chan struct{}, e.workers)
+	var wg sync.WaitGroup
+	for i, batch := range plan.batches {
+		wg.Add(1)
+		go func(i int, batch evaluationBatch) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			responses[i], errs[i] = e.evaluateBatch(ctx, batch, source)
+		}(i, batch)
+	}
+	wg.Wait()
+
+	failedRules := make(map[string]bool)
+	seen := make(map[string]bool)
+	contents := make(map[string]string)
+	var firstErr error
+	failed := 0
+	for i, batch := range plan.batches {
+		if errs[i] != nil {
+			failed++
+			if firstErr == nil {
+				firstErr = errs[i]
+			}
+			for _, f := range batch.files {
+				failedFiles[f] = true
+			}
+			for _, r := range batch.rules {
+				failedRules[r.RuleID] = true
+			}
+			continue
+		}
+		for _, v := range responses[i].normalize() {
+			content, ok := contents[v.FilePath]
+			if _, evaluated := tokens[v.FilePath]; evaluated && !ok {
+				if c, err := source.ReadFile(v.FilePath); err == nil {
+					content, ok = c, true
+					contents[v.FilePath] = c
+				}
+			}
+			verifyViolation(&v, content, ok)
+			key := v.RuleID + "|" + v.FilePath + "|" + strings.TrimSpace(v.CodeSnippet)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			result.Violations = append(result.Violations, v)
+		}
 	}
 
-	violations, score := parseEvaluationResponse(responseText)
+	for _, path := range plan.truncated {
+		if !failedFiles[path] {
+			result.Coverage.FilesPartial++
+		}
+	}
+	result.Coverage.FilesEvaluated = len(paths) - len(failedFiles) - result.Coverage.FilesPartial
+	result.Coverage.RulesEvaluated = len(rules) - countRules(rules, failedRules)
+	result.ComplianceScore = CalculateScore(result.Violations)
 
-	// Recalculate score for consistency
-	if len(violations) > 0 {
-		score = CalculateScore(violations)
+	if failed > 0 {
+		return result, fmt.Errorf("LLM evaluation failed for %d of %d batches: %w", failed, len(plan.batches), firstErr)
 	}
+	return result, nil
+}
 
-	// Count rules evaluated
-	rulesCount := 0
-	for _, p := range policies {
-		if rj, ok := p["rules_json"].(string); ok && rj != "" {
-			rulesCount += 10 // Approximate
+func (e *Evaluator) evaluateBatch(ctx context.Context, batch evaluationBatch, source FileSource) (evaluationResponse, error) {
+	files := make(map[string]string, len(batch.files))
+	promptFiles := make([]llm.PromptFile, 0, len(batch.files))
+	for _, path := range batch.files {
+		content, err := source.ReadFile(path)
+		if err != nil {
+			return evaluationResponse{}, fmt.Errorf("reading %s: %w", path, err)
 		}
+		f := promptFile(path, content, batch.fileBudget)
+		files[f.Path] = f.Content
+		promptFiles = append(promptFiles, f)
 	}
+	prompt := llm.BuildCompliancePrompt(batch.rules, promptFiles)
+	key := e.client.CacheKey(llm.CompliancePromptVersion, ruleSetHash(batch.rules), llm.HashFiles(files))
 
-	return &EvaluationResult{
-		Violations:      violations,
-		ComplianceScore: score,
-		FilesScanned:    len(files),
-		RulesEvaluated:  rulesCount,
-	}, nil
+	var response evaluationResponse
+	err := e.client.GenerateJSONCached(ctx, key, prompt, llm.ComplianceSchema, &response)
+	return response, err
 }
 
-func parseEvaluationResponse(text string) ([]ViolationResult, int) {
-	var result struct {
-		Violations      []ViolationResult `json:"violations"`
-		ComplianceScore int               `json:"compliance_score"`
+// flattenRules lists the rules of all policies in policy order.
+func flattenRules(policies []store.Policy) []llm.ComplianceRule {
+	var rules []llm.ComplianceRule
+	for _, p := range policies {
+		if p.RulesJSON == "" {
+			continue
+		}
+		var parsed struct {
+			Rules []struct {
+				RuleID          string `json:"rule_id"`
+				Title           string `json:"title"`
+				Description     string `json:"description"`
+				Severity        string `json:"severity"`
+				Category        string `json:"category"`
+				ClauseReference string `json:"clause_reference"`
+			} `json:"rules"`
+		}
+		if err := json.Unmarshal([]byte(p.RulesJSON), &parsed); err != nil {
+			continue
+		}
+		for _, r := range parsed.Rules {
+			rules = append(rules, llm.ComplianceRule{
+				RuleID:          r.RuleID,
+				PolicyName:      p.Name,
+				Title:           r.Title,
+				Description:     r.Description,
+				Severity:        r.Severity,
+				Category:        r.Category,
+				ClauseReference: r.ClauseReference,
+			})
+		}
 	}
+	return rules
+}
 
-	if err := llm.ParseJSONResponse(text, &result); err != nil {
-		return nil, 100
+// countRules counts the rules whose IDs are in ids.
+func countRules(rules []llm.ComplianceRule, ids map[string]bool) int {
+	n := 0
+	for _, r := range rules {
+		if ids[r.RuleID] {
+			n++
+		}
 	}
+	return n
+}
+
+// ruleSetHash identifies a set of rules for cache keys.
+func ruleSetHash(rules []llm.ComplianceRule) string {
+	data, _ := json.Marshal(rules)
+	return llm.HashStrings(string(data))
+}
+
+// evaluationResponse is the JSON shape of llm.ComplianceSchema.
+type evaluationResponse struct {
+	Violations      []ViolationResult `json:"violations"`
+	ComplianceScore int               `json:"compliance_score"`
+}
 
+func (r evaluationResponse) normalize() []ViolationResult {
 	// Truncate code snippets
-	for i := range result.Violations {
-		if len(result.Violations[i].CodeSnippet) > 200 {
-			result.Violations[i].CodeSnippet = result.Violations[i].CodeSnippet[:200]
+	for i := range r.Violations {
+		if len(r.Violations[i].CodeSnippet) > 200 {
+			r.Violations[i].CodeSnippet = r.Violations[i].CodeSnippet[:200]
 		}
 	}
 
-	return result.Violations, result.ComplianceScore
+	return r.Violations
 }
@@ -0,0 +1,22 @@
+package compliance
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"strings"
+)
+
+// Fingerprint identifies a finding independently of its line number, so the
+// same violation keeps its fingerprint when unrelated code moves around it.
+// It hashes the rule ID, the slash-separated file path and the code snippet
+// with whitespace collapsed.
+func Fingerprint(ruleID, filePath, snippet string) string {
+	h := sha256.New()
+	h.Write([]byte(strings.ToUpper(strings.TrimSpace(ruleID))))
+	h.Write([]byte{0})
+	h.Write([]byte(filepath.ToSlash(filePath)))
+	h.Write([]byte{0})
+	h.Write([]byte(strings.Join(strings.Fields(snippet), " ")))
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
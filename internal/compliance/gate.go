@@ -0,0 +1,121 @@
+package compliance
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nerifect/nerifect-cli/internal/store"
+)
+
+// severityRank orders severities for the --fail-on threshold.
+var severityRank = map[string]int{
+	"INFO":     0,
+	"LOW":      1,
+	"MEDIUM":   2,
+	"HIGH":     3,
+	"CRITICAL": 4,
+}
+
+// FailOnNone disables the severity gate.
+const FailOnNone = "none"
+
+// GateOptions configures the checks that decide whether a scan fails.
+type GateOptions struct {
+	FailOn        string // lowest severity that fails the scan, or "none"
+	MinScore      int    // minimum compliance score; 0 disables the check
+	MaxViolations int    // maximum number of violations; negative disables the check
+	AllowDegraded bool   // pass scans whose LLM evaluation could not be completed
+}
+
+// DefaultGateOptions fails a scan on critical violations only.
+func DefaultGateOptions() GateOptions {
+	return GateOptions{FailOn: "critical", MaxViolations: -1}
+}
+
+// ParseFailOn validates a --fail-on value and returns it in lower case.
+func ParseFailOn(s string) (string, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == FailOnNone {
+		return s, nil
+	}
+	if _, ok := severityRank[strings.ToUpper(s)]; !ok || s == "info" {
+		return "", fmt.Errorf("invalid fail-on value %q (use critical, high, medium, low or none)", s)
+	}
+	return s, nil
+}
+
+// GateFailure explains why a gate failed the scan.
+type GateFailure struct {
+	Gate   string `json:"gate"`
+	Reason string `json:"reason"`
+}
+
+// EvaluateGates checks a completed scan against the gates and returns one
+// failure per gate that tripped. Suppressed and baselined violations are
+// ignored.
+func EvaluateGates(opts GateOptions, scan *store.Scan, violations []store.Violation) []GateFailure {
+	score := scan.ComplianceScore
+	var active []store.Violation
+	for _, v := range violations {
+		if v.IsActive() {
+			active = append(active, v)
+		}
+	}
+
+	var failures []GateFailure
+
+	if opts.FailOn != "" && opts.FailOn != FailOnNone {
+		threshold := strings.ToUpper(opts.FailOn)
+		counts := make(map[string]int)
+		total := 0
+		for _, v := range active {
+			sev := strings.ToUpper(string(v.Severity))
+			if severityRank[sev] >= severityRank[threshold] {
+				counts[sev]++
+				total++
+			}
+		}
+		if total > 0 {
+			var parts []string
+			for _, sev := range []string{"CRITICAL", "HIGH", "MEDIUM", "LOW"} {
+				if counts[sev] > 0 {
+					parts = append(parts, fmt.Sprintf("%d %s", counts[sev], strings.ToLower(sev)))
+				}
+			}
+			failures = append(failures, GateFailure{
+				Gate:   "fail-on",
+				Reason: fmt.Sprintf("%s at or above %s severity (%s)", plural(total, "violation"), threshold, strings.Join(parts, ", ")),
+			})
+		}
+	}
+
+	if opts.MinScore > 0 && score != nil && *score < opts.MinScore {
+		failures = append(failures, GateFailure{
+			Gate:   "min-score",
+			Reason: fmt.Sprintf("compliance score %d is below the minimum of %d", *score, opts.MinScore),
+		})
+	}
+
+	if opts.MaxViolations >= 0 && len(active) > opts.MaxViolations {
+		failures = append(failures, GateFailure{
+			Gate:   "max-violations",
+			Reason: fmt.Sprintf("%s found, more than the maximum of %d", plural(len(active), "violation"), opts.MaxViolations),
+		})
+	}
+
+	if scan.IsDegraded() && !opts.AllowDegraded {
+		failures = append(failures, GateFailure{
+			Gate:   "degraded",
+			Reason: fmt.Sprintf("scan is incomplete: %s", scan.DegradedReason),
+		})
+	}
+
+	return failures
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -3,41 +3,235 @@ package compliance
 import (
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
+	"sync"
+	"unicode/utf8"
 
 	"github.com/gobwas/glob"
 	"github.com/nerifect/nerifect-cli/internal/store"
 )
 
+// DefaultMaxMatchesPerRule caps how many violations a single rule can report.
+const DefaultMaxMatchesPerRule = 50
+
 // PatternChecker evaluates policy rules using regex/glob pattern matching.
-type PatternChecker struct{}
+// Files are checked one at a time with CheckFile, which is safe for
+// concurrent use, and the per-rule cap is applied by Limit once the results
+// are merged in path order.
+type PatternChecker struct {
+	maxMatchesPerRule int
+	pathRules         []pathRule
+	codeRules         []codeRule
 
-func NewPatternChecker() *PatternChecker {
-	return &PatternChecker{}
+	mu     sync.Mutex
+	capped map[string]bool
 }
 
-// Check evaluates rules against file paths and contents. Returns violations found.
-func (pc *PatternChecker) Check(rules []store.PolicyRule, files map[string]string, allPaths []string) []ViolationResult {
-	var violations []ViolationResult
-	regexCache := make(map[string]*regexp.Regexp)
+// pathRule is a FILE_PATTERN rule, checked against file paths.
+type pathRule struct {
+	rule    store.PolicyRule
+	pattern string
+	scope   fileScope
+}
+
+// codeRule is a CODE_PATTERN or CONFIG_CHECK rule with its compiled regex,
+// checked against file contents.
+type codeRule struct {
+	rule  store.PolicyRule
+	re    *regexp.Regexp
+	scope fileScope
+}
 
+// NewPatternChecker prepares rules for checking and reports at most
+// maxMatchesPerRule violations per rule across all files. Zero or less uses
+// the default.
+func NewPatternChecker(rules []store.PolicyRule, maxMatchesPerRule int) *PatternChecker {
+	if maxMatchesPerRule <= 0 {
+		maxMatchesPerRule = DefaultMaxMatchesPerRule
+	}
+	pc := &PatternChecker{
+		maxMatchesPerRule: maxMatchesPerRule,
+		capped:            make(map[string]bool),
+	}
+
+	regexCache := make(map[string]*regexp.Regexp)
 	for _, rule := range rules {
-		ct := strings.ToUpper(rule.CheckType)
 		pattern := strings.TrimSpace(rule.Pattern)
 		if pattern == "" {
 			continue
 		}
-
-		switch ct {
+		switch strings.ToUpper(rule.CheckType) {
 		case "FILE_PATTERN":
-			violations = append(violations, pc.checkFilePattern(rule, pattern, allPaths)...)
+			pc.pathRules = append(pc.pathRules, pathRule{rule: rule, pattern: pattern, scope: newFileScope(rule)})
 		case "CODE_PATTERN", "CONFIG_CHECK":
-			violations = append(violations, pc.checkCodePattern(rule, pattern, files, regexCache)...)
+			if strings.HasPrefix(strings.ToLower(pattern), "missing:") {
+				continue
+			}
+			re, ok := regexCache[pattern]
+			if !ok {
+				var err error
+				re, err = regexp.Compile("(?im)" + pattern)
+				if err != nil {
+					continue
+				}
+				regexCache[pattern] = re
+			}
+			pc.codeRules = append(pc.codeRules, codeRule{rule: rule, re: re, scope: newFileScope(rule)})
+		}
+	}
+	return pc
+}
+
+// HasCodeRules reports whether any rule needs file contents.
+func (pc *PatternChecker) HasCodeRules() bool {
+	return len(pc.codeRules) > 0
+}
+
+// CappedRules returns the IDs of rules that hit the match cap.
+func (pc *PatternChecker) CappedRules() []string {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	var ids []string
+	for id := range pc.capped {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (pc *PatternChecker) markCapped(ruleID string) {
+	pc.mu.Lock()
+	pc.capped[ruleID] = true
+	pc.mu.Unlock()
+}
+
+// CheckPaths evaluates the FILE_PATTERN rules against all scanned paths.
+func (pc *PatternChecker) CheckPaths(allPaths []string) []ViolationResult {
+	var violations []ViolationResult
+	for _, r := range pc.pathRules {
+		violations = append(violations, pc.checkFilePattern(r.rule, r.pattern, r.scope.filterPaths(allPaths))...)
+	}
+	return violations
+}
+
+// CheckFile evaluates the code rules against the content of one file. It
+// returns at most the cap of matches per rule; Limit applies the cap across
+// files.
+func (pc *PatternChecker) CheckFile(path, content string) []ViolationResult {
+	var violations []ViolationResult
+	var idx *lineIndex
+	for _, r := range pc.codeRules {
+		if !r.scope.contains(path) {
+			continue
+		}
+		matches := r.re.FindAllStringIndex(content, pc.maxMatchesPerRule+1)
+		if len(matches) == 0 {
+			continue
+		}
+		if idx == nil {
+			idx = newLineIndex(content)
+		}
+		for _, loc := range matches {
+			v := makeViolation(r.rule, path, idx.snippet(loc[0], loc[1]))
+			v.LineStart, v.ColumnStart = idx.position(loc[0])
+			v.LineEnd, v.ColumnEnd = v.LineStart, v.ColumnStart
+			if loc[1] > loc[0] {
+				// End column is exclusive: one past the last matched character
+				v.LineEnd, v.ColumnEnd = idx.position(loc[1] - 1)
+				v.ColumnEnd++
+			}
+			violations = append(violations, v)
 		}
 	}
 	return violations
 }
 
+// Limit keeps the first maxMatchesPerRule violations of each rule. Callers
+// pass the CheckFile results of all files in path order, so the cap keeps the
+// same matches every run.
+func (pc *PatternChecker) Limit(violations []ViolationResult) []ViolationResult {
+	counts := make(map[string]int)
+	var kept []ViolationResult
+	for _, v := range violations {
+		if counts[v.RuleID] >= pc.maxMatchesPerRule {
+			pc.markCapped(v.RuleID)
+			continue
+		}
+		counts[v.RuleID]++
+		kept = append(kept, v)
+	}
+	return kept
+}
+
+// fileScope restricts a rule to the paths matching its applies_to globs
+// (all paths when empty) and none of its exclude globs.
+type fileScope struct {
+	appliesTo []glob.Glob
+	exclude   []glob.Glob
+}
+
+func newFileScope(rule store.PolicyRule) fileScope {
+	return fileScope{
+		appliesTo: compileGlobs(rule.AppliesTo),
+		exclude:   compileGlobs(rule.Exclude),
+	}
+}
+
+func compileGlobs(patterns []string) []glob.Glob {
+	var globs []glob.Glob
+	for _, p := range patterns {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		g, err := glob.Compile(p)
+		if err != nil {
+			// Treat an invalid glob as a literal so it still narrows the scope
+			g = literalGlob(p)
+		}
+		globs = append(globs, g)
+	}
+	return globs
+}
+
+// literalGlob matches paths containing s, mirroring pathMatches' fallback.
+type literalGlob string
+
+func (l literalGlob) Match(path string) bool {
+	return strings.Contains(path, string(l))
+}
+
+func (fs fileScope) contains(path string) bool {
+	if len(fs.appliesTo) > 0 && !matchesAny(fs.appliesTo, path) {
+		return false
+	}
+	return !matchesAny(fs.exclude, path)
+}
+
+func (fs fileScope) filterPaths(paths []string) []string {
+	if len(fs.appliesTo) == 0 && len(fs.exclude) == 0 {
+		return paths
+	}
+	var out []string
+	for _, p := range paths {
+		if fs.contains(p) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func matchesAny(globs []glob.Glob, path string) bool {
+	base := filepath.Base(path)
+	for _, g := range globs {
+		if g.Match(path) || g.Match(base) {
+			return true
+		}
+	}
+	return false
+}
+
 func (pc *PatternChecker) checkFilePattern(rule store.PolicyRule, pattern string, paths []string) []ViolationResult {
 	var violations []ViolationResult
 
@@ -49,6 +243,10 @@ func (pc *PatternChecker) checkFilePattern(rule store.PolicyRule, pattern string
 	} else {
 		for _, p := range paths {
 			if pathMatches(p, pattern) {
+				if len(violations) >= pc.maxMatchesPerRule {
+					pc.markCapped(rule.RuleID)
+					break
+				}
 				violations = append(violations, makeViolation(rule, p, ""))
 			}
 		}
@@ -56,34 +254,40 @@ func (pc *PatternChecker) checkFilePattern(rule store.PolicyRule, pattern string
 	return violations
 }
 
-func (pc *PatternChecker) checkCodePattern(rule store.PolicyRule, pattern string, files map[string]string, cache map[string]*regexp.Regexp) []ViolationResult {
-	if strings.HasPrefix(strings.ToLower(pattern), "missing:") {
-		return nil
-	}
+// lineIndex maps byte offsets in a file to 1-based line and column numbers.
+type lineIndex struct {
+	content string
+	starts  []int // byte offset of the start of each line
+}
 
-	var violations []ViolationResult
-	re, ok := cache[pattern]
-	if !ok {
-		var err error
-		re, err = regexp.Compile("(?im)" + pattern)
-		if err != nil {
-			return nil
+func newLineIndex(content string) *lineIndex {
+	starts := []int{0}
+	for i := 0; i < len(content); i++ {
+		if content[i] == '\n' {
+			starts = append(starts, i+1)
 		}
-		cache[pattern] = re
 	}
+	return &lineIndex{content: content, starts: starts}
+}
 
-	for path, content := range files {
-		loc := re.FindStringIndex(content)
-		if loc != nil {
-			end := loc[0] + 200
-			if end > len(content) {
-				end = len(content)
-			}
-			snippet := content[loc[0]:end]
-			violations = append(violations, makeViolation(rule, path, snippet))
-		}
+// position returns the line and column of a byte offset. Columns count characters, not bytes.
+func (li *lineIndex) position(offset int) (line, col int) {
+	line = sort.Search(len(li.starts), func(i int) bool { return li.starts[i] > offset })
+	col = utf8.RuneCountInString(li.content[li.starts[line-1]:offset]) + 1
+	return line, col
+}
+
+// snippet returns the full lines spanned by the byte range [start, end).
+func (li *lineIndex) snippet(start, end int) string {
+	line, _ := li.position(start)
+	from := li.starts[line-1]
+	to := strings.IndexByte(li.content[max(end-1, start):], '\n')
+	if to < 0 {
+		to = len(li.content)
+	} else {
+		to += max(end-1, start)
 	}
-	return violations
+	return strings.TrimSpace(li.content[from:to])
 }
 
 func makeViolation(rule store.PolicyRule, filePath, snippet string) ViolationResult {
@@ -1,6 +1,9 @@
 package compliance
 
-import "strings"
+import (
+	"strings"
+	"time"
+)
 
 // SeverityWeights maps severity levels to score penalties.
 var SeverityWeights = map[string]int{
@@ -12,11 +15,12 @@ var SeverityWeights = map[string]int{
 }
 
 // CalculateScore computes compliance score: 100 minus severity-weighted penalties per unique rule.
+// Suppressed, baselined and unverified violations do not count.
 func CalculateScore(violations []ViolationResult) int {
 	seenRules := make(map[string]bool)
 	penalty := 0
 	for _, v := range violations {
-		if seenRules[v.RuleID] {
+		if v.Suppressed || v.Baselined || v.Unverified || seenRules[v.RuleID] {
 			continue
 		}
 		seenRules[v.RuleID] = true
@@ -35,15 +39,31 @@ func CalculateScore(violations []ViolationResult) int {
 
 // ViolationResult is used by the scorer and evaluator.
 type ViolationResult struct {
-	RuleID          string `json:"rule_id"`
-	PolicyName      string `json:"policy_name"`
-	Severity        string `json:"severity"`
-	Title           string `json:"title"`
-	Description     string `json:"description"`
-	FilePath        string `json:"file_path"`
-	LineStart       int    `json:"line_start"`
-	LineEnd         int    `json:"line_end"`
-	CodeSnippet     string `json:"code_snippet"`
-	ClauseReference string `json:"clause_reference"`
-	Recommendation  string `json:"recommendation"`
+	RuleID            string `json:"rule_id"`
+	PolicyName        string `json:"policy_name"`
+	Severity          string `json:"severity"`
+	Title             string `json:"title"`
+	Description       string `json:"description"`
+	FilePath          string `json:"file_path"`
+	LineStart         int    `json:"line_start"`
+	LineEnd           int    `json:"line_end"`
+	ColumnStart       int    `json:"column_start,omitempty"`
+	ColumnEnd         int    `json:"column_end,omitempty"`
+	CodeSnippet       string `json:"code_snippet"`
+	ClauseReference   string `json:"clause_reference"`
+	Recommendation    string `json:"recommendation"`
+	Suppressed        bool   `json:"suppressed,omitempty"`
+	SuppressionReason string `json:"suppression_reason,omitempty"`
+	Baselined         bool   `json:"baselined,omitempty"`
+	// Unverified is set on LLM violations whose snippet was not found in the file
+	Unverified bool `json:"unverified,omitempty"`
+	// Commit is set on violations found in git history
+	Commit *Commit `json:"-"`
+}
+
+// Commit identifies the git commit that added the lines of a violation.
+type Commit struct {
+	SHA    string
+	Author string
+	Date   time.Time
 }
@@ -0,0 +1,107 @@
+package compliance
+
+import (
+	"regexp"
+	"strings"
+)
+
+// suppressionRe matches inline suppression comments such as
+//
+//	# nerifect:ignore CIS-DOCKER-1 reason="base image needs root for setup"
+//
+// Several rule IDs may be listed separated by commas.
+var suppressionRe = regexp.MustCompile(`nerifect:ignore\s+([A-Za-z0-9_.\-]+(?:\s*,\s*[A-Za-z0-9_.\-]+)*)(?:\s+reason\s*=\s*"([^"]*)")?`)
+
+// Suppression is a nerifect:ignore comment found in a file.
+type Suppression struct {
+	RuleIDs []string
+	Reason  string
+	Line    int
+}
+
+// covers reports whether the suppression applies to ruleID at line. A comment
+// suppresses findings on its own line and on the line directly below it.
+func (s Suppression) covers(ruleID string, line int) bool {
+	if line != s.Line && line != s.Line+1 {
+		return false
+	}
+	for _, id := range s.RuleIDs {
+		if strings.EqualFold(id, ruleID) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseSuppressions returns the nerifect:ignore comments in content.
+func ParseSuppressions(content string) []Suppression {
+	if !strings.Contains(content, "nerifect:ignore") {
+		return nil
+	}
+	var sups []Suppression
+	for i, line := range strings.Split(content, "\n") {
+		m := suppressionRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		var ids []string
+		for _, id := range strings.Split(m[1], ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				ids = append(ids, id)
+			}
+		}
+		sups = append(sups, Suppression{RuleIDs: ids, Reason: strings.TrimSpace(m[2]), Line: i + 1})
+	}
+	return sups
+}
+
+// ApplySuppressions marks the violations covered by a nerifect:ignore comment
+// in their file as suppressed, recording the comment's reason. Violations
+// without a line number are located by their code snippet.
+func ApplySuppressions(violations []ViolationResult, files map[string]string) []ViolationResult {
+	parsed := make(map[string][]Suppression)
+	for i, v := range violations {
+		content, ok := files[v.FilePath]
+		if !ok {
+			continue
+		}
+		sups, seen := parsed[v.FilePath]
+		if !seen {
+			sups = ParseSuppressions(content)
+			parsed[v.FilePath] = sups
+		}
+		if len(sups) == 0 {
+			continue
+		}
+
+		line := v.LineStart
+		if line <= 0 {
+			line = snippetLine(content, v.CodeSnippet)
+		}
+		if line <= 0 {
+			continue
+		}
+		for _, s := range sups {
+			if s.covers(v.RuleID, line) {
+				violations[i].Suppressed = true
+				violations[i].SuppressionReason = s.Reason
+				break
+			}
+		}
+	}
+	return violations
+}
+
+// snippetLine returns the 1-based line where the first line of snippet occurs
+// in content, or 0 if it cannot be found.
+func snippetLine(content, snippet string) int {
+	first := strings.TrimSpace(strings.SplitN(strings.TrimSpace(snippet), "\n", 2)[0])
+	if first == "" {
+		return 0
+	}
+	idx := strings.Index(content, first)
+	if idx < 0 {
+		return 0
+	}
+	return strings.Count(content[:idx], "\n") + 1
+}
@@ -0,0 +1,152 @@
+package compliance
+
+import (
+	"regexp"
+	"strings"
+)
+
+// minSnippetSimilarity is how similar, on average, the lines of a snippet
+// must be to the lines of the file for the snippet to count as found.
+const minSnippetSimilarity = 0.8
+
+// minSnippetChars is the fewest non-space characters a snippet, or a snippet
+// line matched by containment, must have. Shorter text such as "}" or "("
+// occurs in almost any file and says nothing about where a violation is.
+const minSnippetChars = 8
+
+// lineNumberPrefix matches a line number prefix as written by
+// llm.NumberLines, which models sometimes copy into snippets.
+var lineNumberPrefix = regexp.MustCompile(`^\s*\d+\|\s?`)
+
+// verifyViolation checks that the code snippet of an LLM violation exists in
+// the file it names. A found snippet replaces the reported line range and
+// snippet with the matching lines of the file; otherwise the violation is
+// marked unverified. content is the full file and exists reports whether the
+// file was part of the evaluation.
+func verifyViolation(v *ViolationResult, content string, exists bool) {
+	if !exists {
+		v.Unverified = true
+		return
+	}
+	start, end, ok := locateSnippet(content, v.CodeSnippet, v.LineStart)
+	if !ok {
+		v.Unverified = true
+		return
+	}
+
+	lines := strings.Split(content, "\n")
+	v.LineStart, v.LineEnd = start, end
+	v.ColumnStart, v.ColumnEnd = 0, 0
+	v.CodeSnippet = truncSnippet(strings.TrimSpace(strings.Join(lines[start-1:end], "\n")), 200)
+	v.Unverified = false
+}
+
+// locateSnippet finds the lines of content that best match snippet, allowing
+// for differences in whitespace, copied line numbers and small edits. When
+// several places match equally well, the one closest to hint wins. It returns
+// the 1-based line range of the match.
+func locateSnippet(content, snippet string, hint int) (start, end int, ok bool) {
+	want := snippetLines(snippet)
+	if nonSpaceChars(strings.Join(want, "")) < minSnippetChars {
+		return 0, 0, false
+	}
+
+	fileLines := strings.Split(content, "\n")
+	var (
+		normalized []string
+		lineNums   []int
+	)
+	for i, line := range fileLines {
+		if n := normalizeLine(line); n != "" {
+			normalized = append(normalized, n)
+			lineNums = append(lineNums, i+1)
+		}
+	}
+
+	best := 0.0
+	for i := 0; i+len(want) <= len(normalized); i++ {
+		// The first line decides cheaply whether the window is worth scoring
+		total := lineSimilarity(normalized[i], want[0])
+		if total < minSnippetSimilarity {
+			continue
+		}
+		for j := 1; j < len(want); j++ {
+			total += lineSimilarity(normalized[i+j], want[j])
+		}
+		score := total / float64(len(want))
+		if score < minSnippetSimilarity {
+			continue
+		}
+		s, e := lineNums[i], lineNums[i+len(want)-1]
+		if score > best || (score == best && distance(s, hint) < distance(start, hint)) {
+			best, start, end, ok = score, s, e, true
+		}
+	}
+	return start, end, ok
+}
+
+// snippetLines normalizes the non-empty lines of a snippet, dropping line
+// number prefixes and elisions such as "...".
+func snippetLines(snippet string) []string {
+	var lines []string
+	for _, line := range strings.Split(snippet, "\n") {
+		line = lineNumberPrefix.ReplaceAllString(line, "")
+		line = normalizeLine(line)
+		line = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(line, "..."), "..."))
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// normalizeLine collapses runs of whitespace and trims the line.
+func normalizeLine(line string) string {
+	return strings.Join(strings.Fields(line), " ")
+}
+
+// lineSimilarity scores how well a snippet line matches a file line, from 0
+// to 1. A snippet line that is part of the file line, as when the model
+// quotes only the relevant expression, matches fully if it is at least
+// minSnippetChars long; shorter lines are only scored by similarity.
+func lineSimilarity(fileLine, snippetLine string) float64 {
+	if nonSpaceChars(snippetLine) >= minSnippetChars && strings.Contains(fileLine, snippetLine) {
+		return 1
+	}
+	return diceCoefficient(fileLine, snippetLine)
+}
+
+// nonSpaceChars counts the characters of s that are not whitespace.
+func nonSpaceChars(s string) int {
+	return len(strings.Join(strings.Fields(s), ""))
+}
+
+// diceCoefficient is the Sørensen–Dice similarity of the character bigrams of
+// two strings.
+func diceCoefficient(a, b string) float64 {
+	if len(a) < 2 || len(b) < 2 {
+		if a == b {
+			return 1
+		}
+		return 0
+	}
+	bigrams := make(map[string]int, len(a))
+	for i := 0; i+1 < len(a); i++ {
+		bigrams[a[i:i+2]]++
+	}
+	shared := 0
+	for i := 0; i+1 < len(b); i++ {
+		if bigrams[b[i:i+2]] > 0 {
+			bigrams[b[i:i+2]]--
+			shared++
+		}
+	}
+	return 2 * float64(shared) / float64(len(a)+len(b)-2)
+}
+
+func distance(line, hint int) int {
+	if line > hint {
+		return line - hint
+	}
+	return hint - line
+}
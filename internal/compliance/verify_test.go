@@ -0,0 +1,76 @@
+package compliance
+
+import "testing"
+
+const verifyFile = `package main
+
+import "os"
+
+func main() {
+	key := os.Getenv("API_KEY")
+	password := "hunter2"
+	println(key, password)
+}
+
+func helper() {
+	password := "hunter2"
+	_ = password
+}
+`
+
+func TestLocateSnippet(t *testing.T) {
+	tests := []struct {
+		name       string
+		snippet    string
+		hint       int
+		start, end int
+		ok         bool
+	}{
+		{name: "exact line", snippet: `key := os.Getenv("API_KEY")`, hint: 6, start: 6, end: 6, ok: true},
+		{name: "whitespace differs", snippet: "key   :=   os.Getenv(\"API_KEY\")", hint: 1, start: 6, end: 6, ok: true},
+		{name: "multiple lines", snippet: "key := os.Getenv(\"API_KEY\")\n\tpassword := \"hunter2\"", hint: 6, start: 6, end: 7, ok: true},
+		{name: "expression within line", snippet: `os.Getenv("API_KEY")`, hint: 1, start: 6, end: 6, ok: true},
+		{name: "line number prefixes", snippet: " 6| \tkey := os.Getenv(\"API_KEY\")\n 7| \tpassword := \"hunter2\"", hint: 1, start: 6, end: 7, ok: true},
+		{name: "elided", snippet: "...\nkey := os.Getenv(\"API_KEY\")\n...", hint: 1, start: 6, end: 6, ok: true},
+		{name: "elision markers on the line", snippet: `... println(key, password) ...`, hint: 1, start: 8, end: 8, ok: true},
+		{name: "small edit", snippet: `key := os.Getenv("API_KEYS")`, hint: 1, start: 6, end: 6, ok: true},
+		{name: "closest to hint", snippet: `password := "hunter2"`, hint: 12, start: 12, end: 12, ok: true},
+		{name: "closest to earlier hint", snippet: `password := "hunter2"`, hint: 2, start: 7, end: 7, ok: true},
+		{name: "not in file", snippet: `db.Exec("DROP TABLE users")`, hint: 6},
+		{name: "empty", snippet: "", hint: 6},
+		{name: "closing brace", snippet: "}", hint: 9},
+		{name: "single letter", snippet: "a", hint: 1},
+		{name: "single character in a line", snippet: "e", hint: 1},
+		{name: "parenthesis", snippet: "(", hint: 1},
+		{name: "trivial lines", snippet: "}\n\n}", hint: 9},
+		{name: "short token", snippet: "key", hint: 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, ok := locateSnippet(verifyFile, tt.snippet, tt.hint)
+			if ok != tt.ok || start != tt.start || end != tt.end {
+				t.Errorf("locateSnippet(%q) = %d, %d, %v; want %d, %d, %v", tt.snippet, start, end, ok, tt.start, tt.end, tt.ok)
+			}
+		})
+	}
+}
+
+func TestVerifyViolation(t *testing.T) {
+	v := ViolationResult{CodeSnippet: `password := "hunter2"`, LineStart: 13, LineEnd: 14}
+	verifyViolation(&v, verifyFile, true)
+	if v.Unverified || v.LineStart != 12 || v.LineEnd != 12 {
+		t.Errorf("verifyViolation = lines %d-%d, unverified %v; want lines 12-12 verified", v.LineStart, v.LineEnd, v.Unverified)
+	}
+
+	v = ViolationResult{CodeSnippet: "}", LineStart: 3, LineEnd: 3}
+	verifyViolation(&v, verifyFile, true)
+	if !v.Unverified || v.LineStart != 3 {
+		t.Errorf("verifyViolation with a trivial snippet = lines %d-%d, unverified %v; want line 3 unverified", v.LineStart, v.LineEnd, v.Unverified)
+	}
+
+	v = ViolationResult{CodeSnippet: `password := "hunter2"`, LineStart: 7}
+	verifyViolation(&v, "", false)
+	if !v.Unverified {
+		t.Error("verifyViolation verified a snippet in a file that was not evaluated")
+	}
+}
@@ -5,48 +5,118 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/nerifect/nerifect-cli/internal/llm"
 	"gopkg.in/yaml.v3"
 )
 
 // RepoConfig represents a tracked repository with its scan settings.
 type RepoConfig struct {
-	Name     string  `yaml:"name" json:"name"`
-	Path     string  `yaml:"path,omitempty" json:"path,omitempty"`
-	URL      string  `yaml:"url,omitempty" json:"url,omitempty"`
-	Branch   string  `yaml:"branch,omitempty" json:"branch,omitempty"`
-	ScanType string  `yaml:"scan_type,omitempty" json:"scan_type,omitempty"`
-	Policies []int64 `yaml:"policies,omitempty" json:"policies,omitempty"`
+	Name     string `yaml:"name" json:"name"`
+	Path     string `yaml:"path,omitempty" json:"path,omitempty"`
+	URL      string `yaml:"url,omitempty" json:"url,omitempty"`
+	Branch   string `yaml:"branch,omitempty" json:"branch,omitempty"`
+	ScanType string `yaml:"scan_type,omitempty" json:"scan_type,omitempty"`
+
+	// Policies to scan with: IDs, names, preset slugs or regulation types,
+	// optionally narrowed to policy categories
+	Policies   []string `yaml:"policies,omitempty" json:"policies,omitempty"`
+	Categories []string `yaml:"categories,omitempty" json:"categories,omitempty"`
+
+	// Quality gates applied when scanning this repo; scan flags take precedence
+	FailOn        string `yaml:"fail_on,omitempty" json:"fail_on,omitempty"`
+	MinScore      int    `yaml:"min_score,omitempty" json:"min_score,omitempty"`
+	MaxViolations *int   `yaml:"max_violations,omitempty" json:"max_violations,omitempty"`
+
+	// File selection for this repo; each setting that is set replaces the
+	// global one
+	ExcludeDirs       []string `yaml:"exclude_dirs,omitempty" json:"exclude_dirs,omitempty"`
+	IncludeDirs       []string `yaml:"include_dirs,omitempty" json:"include_dirs,omitempty"`
+	ExcludeExtensions []string `yaml:"exclude_extensions,omitempty" json:"exclude_extensions,omitempty"`
+	IncludeExtensions []string `yaml:"include_extensions,omitempty" json:"include_extensions,omitempty"`
+	MaxFileSizeKB     int      `yaml:"max_file_size_kb,omitempty" json:"max_file_size_kb,omitempty"`
 }
 
 type Config struct {
-	LLMProvider     string       `yaml:"llm_provider" json:"llm_provider"`
-	GeminiAPIKey    string       `yaml:"gemini_api_key" json:"-"`
-	OpenAIAPIKey    string       `yaml:"openai_api_key" json:"-"`
-	AnthropicAPIKey string       `yaml:"anthropic_api_key" json:"-"`
-	GithubToken     string       `yaml:"github_token" json:"-"`
-	DefaultModel    string       `yaml:"default_model" json:"default_model"`
-	OutputFormat    string       `yaml:"output_format" json:"output_format"`
-	DataDir         string       `yaml:"data_dir" json:"data_dir"`
-	DatabasePath    string       `yaml:"database_path" json:"database_path"`
-	MaxFilesPerScan int          `yaml:"max_files_per_scan" json:"max_files_per_scan"`
-	MaxFileSizeKB      int          `yaml:"max_file_size_kb" json:"max_file_size_kb"`
-	AgentCheckInterval int          `yaml:"agent_check_interval" json:"agent_check_interval"`
-	Repos              []RepoConfig `yaml:"repos,omitempty" json:"repos,omitempty"`
+	LLMProvider          string       `yaml:"llm_provider" json:"llm_provider"`
+	GeminiAPIKey         string       `yaml:"gemini_api_key" json:"-"`
+	OpenAIAPIKey         string       `yaml:"openai_api_key" json:"-"`
+	AnthropicAPIKey      string       `yaml:"anthropic_api_key" json:"-"`
+	LocalAPIKey          string       `yaml:"local_api_key" json:"-"`
+	AzureOpenAIAPIKey    string       `yaml:"azure_openai_api_key" json:"-"`
+	BedrockAPIKey        string       `yaml:"bedrock_api_key" json:"-"`
+	LLMBaseURL           string       `yaml:"llm_base_url" json:"llm_base_url"`
+	LLMMaxRetries        int          `yaml:"llm_max_retries" json:"llm_max_retries"`
+	LLMTimeoutSeconds    int          `yaml:"llm_timeout_seconds" json:"llm_timeout_seconds"`
+	LLMRequestsPerMinute int          `yaml:"llm_requests_per_minute" json:"llm_requests_per_minute"`
+	LLMCacheTTLHours     int          `yaml:"llm_cache_ttl_hours" json:"llm_cache_ttl_hours"`
+	LLMBatchTokens       int          `yaml:"llm_batch_tokens" json:"llm_batch_tokens"`
+	LLMMaxConcurrency    int          `yaml:"llm_max_concurrency" json:"llm_max_concurrency"`
+	GithubToken          string       `yaml:"github_token" json:"-"`
+	GitlabToken          string       `yaml:"gitlab_token" json:"-"`
+	BitbucketToken       string       `yaml:"bitbucket_token" json:"-"`
+	GitToken             string       `yaml:"git_token" json:"-"`
+	GithubHosts          []string     `yaml:"github_hosts" json:"github_hosts"`
+	GitlabHosts          []string     `yaml:"gitlab_hosts" json:"gitlab_hosts"`
+	BitbucketHosts       []string     `yaml:"bitbucket_hosts" json:"bitbucket_hosts"`
+	DefaultModel         string       `yaml:"default_model" json:"default_model"`
+	OutputFormat         string       `yaml:"output_format" json:"output_format"`
+	DataDir              string       `yaml:"data_dir" json:"data_dir"`
+	DatabasePath         string       `yaml:"database_path" json:"database_path"`
+	MaxFilesPerScan      int          `yaml:"max_files_per_scan" json:"max_files_per_scan"`
+	MaxFileSizeKB        int          `yaml:"max_file_size_kb" json:"max_file_size_kb"`
+	MaxMatchesPerRule    int          `yaml:"max_matches_per_rule" json:"max_matches_per_rule"`
+	RespectGitignore     bool         `yaml:"respect_gitignore" json:"respect_gitignore"`
+	ExcludeDirs          []string     `yaml:"exclude_dirs,omitempty" json:"exclude_dirs,omitempty"`
+	IncludeDirs          []string     `yaml:"include_dirs" json:"include_dirs"`
+	ExcludeExtensions    []string     `yaml:"exclude_extensions,omitempty" json:"exclude_extensions,omitempty"`
+	IncludeExtensions    []string     `yaml:"include_extensions" json:"include_extensions"`
+	AgentCheckInterval   int          `yaml:"agent_check_interval" json:"agent_check_interval"`
+	Repos                []RepoConfig `yaml:"repos,omitempty" json:"repos,omitempty"`
+
+	// LLMPrices overrides the built-in per-model prices used for cost
+	// estimates, in US dollars per million tokens
+	LLMPrices map[string]llm.Price `yaml:"llm_prices,omitempty" json:"llm_prices,omitempty"`
 }
 
+// Directories and file extensions excluded from scans when exclude_dirs and
+// exclude_extensions are not set: dependency, build and tool directories, and
+// binary formats. They are not written to the config file, so changes to them
+// reach every user who has not set their own list.
+var (
+	DefaultExcludeDirs = []string{
+		"node_modules", ".git", "__pycache__", ".venv", "venv", "vendor", "dist",
+		"build", ".next", ".nuxt", "target", ".idea", ".vscode", "coverage",
+		".cache", ".tox", ".mypy_cache", ".pytest_cache", "env", ".env", ".terraform",
+	}
+	DefaultExcludeExtensions = []string{
+		".png", ".jpg", ".jpeg", ".gif", ".webp", ".pdf", ".zip", ".tar", ".gz", ".tgz",
+		".jar", ".exe", ".dmg", ".woff", ".woff2", ".ttf", ".ico", ".svg", ".mp3", ".mp4",
+		".mov", ".avi", ".so", ".dylib", ".dll", ".a", ".o", ".pyc", ".class", ".wasm",
+		".bmp", ".eot", ".otf", ".db", ".sqlite",
+	}
+)
+
 func DefaultConfig() *Config {
 	homeDir, _ := os.UserHomeDir()
 	dataDir := filepath.Join(homeDir, ".nerifect")
 	return &Config{
-		LLMProvider:     "gemini",
-		DefaultModel:    "gemini-2.0-flash",
-		OutputFormat:    "table",
-		DataDir:         dataDir,
-		DatabasePath:    filepath.Join(dataDir, "nerifect.db"),
+		LLMProvider:        "gemini",
+		LLMMaxRetries:      llm.DefaultMaxRetries,
+		LLMTimeoutSeconds:  int(llm.DefaultTimeout / time.Second),
+		LLMCacheTTLHours:   7 * 24,
+		LLMBatchTokens:     30000,
+		LLMMaxConcurrency:  4,
+		DefaultModel:       "gemini-2.0-flash",
+		OutputFormat:       "table",
+		DataDir:            dataDir,
+		DatabasePath:       filepath.Join(dataDir, "nerifect.db"),
 		MaxFilesPerScan:    800,
 		MaxFileSizeKB:      80,
+		MaxMatchesPerRule:  50,
 		AgentCheckInterval: 24,
 	}
 }
@@ -70,18 +140,27 @@ func Load() (*Config, error) {
 	if v := os.Getenv("NERIFECT_PROVIDER"); v != "" {
 		cfg.LLMProvider = v
 	}
-	if v := os.Getenv("GEMINI_API_KEY"); v != "" {
-		cfg.GeminiAPIKey = v
-	}
-	if v := os.Getenv("OPENAI_API_KEY"); v != "" {
-		cfg.OpenAIAPIKey = v
+	for _, name := range llm.ProviderNames() {
+		p := llm.ProviderFor(name)
+		if v := os.Getenv(p.APIKeyEnvVar()); v != "" {
+			cfg.setField(p.APIKeyConfigKey(), v)
+		}
 	}
-	if v := os.Getenv("ANTHROPIC_API_KEY"); v != "" {
-		cfg.AnthropicAPIKey = v
+	if v := os.Getenv("NERIFECT_LLM_BASE_URL"); v != "" {
+		cfg.LLMBaseURL = v
 	}
 	if v := os.Getenv("GITHUB_TOKEN"); v != "" {
 		cfg.GithubToken = v
 	}
+	if v := os.Getenv("GITLAB_TOKEN"); v != "" {
+		cfg.GitlabToken = v
+	}
+	if v := os.Getenv("BITBUCKET_TOKEN"); v != "" {
+		cfg.BitbucketToken = v
+	}
+	if v := os.Getenv("NERIFECT_GIT_TOKEN"); v != "" {
+		cfg.GitToken = v
+	}
 	if v := os.Getenv("NERIFECT_MODEL"); v != "" {
 		cfg.DefaultModel = v
 	}
@@ -128,23 +207,61 @@ func (c *Config) Save() error {
 }
 
 func (c *Config) Set(key, value string) error {
+	if err := c.setField(key, value); err != nil {
+		return err
+	}
+	return c.Save()
+}
+
+// listDefaults holds the built-in values of list keys that default to a list
+// rather than to empty.
+var listDefaults = map[string][]string{
+	"exclude_dirs":       DefaultExcludeDirs,
+	"exclude_extensions": DefaultExcludeExtensions,
+}
+
+// yamlKey returns the config key of a field: its yaml name without options.
+func yamlKey(field reflect.StructField) string {
+	key, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	return key
+}
+
+// setField assigns a value to the field tagged with the given yaml key.
+func (c *Config) setField(key, value string) error {
 	v := reflect.ValueOf(c).Elem()
 	t := v.Type()
 
 	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
-		tag := field.Tag.Get("yaml")
-		if tag == key {
+		if yamlKey(field) == key {
 			f := v.Field(i)
 			if f.Kind() == reflect.String {
 				f.SetString(value)
-				return c.Save()
+				return nil
 			}
 			if f.Kind() == reflect.Int {
 				var n int
 				fmt.Sscanf(value, "%d", &n)
 				f.SetInt(int64(n))
-				return c.Save()
+				return nil
+			}
+			if f.Kind() == reflect.Slice && f.Type().Elem().Kind() == reflect.String {
+				var items []string
+				for _, item := range strings.Split(value, ",") {
+					if item = strings.TrimSpace(item); item != "" {
+						items = append(items, item)
+					}
+				}
+				f.Set(reflect.ValueOf(items))
+				return nil
+			}
+			if f.Kind() == reflect.Bool {
+				b, err := strconv.ParseBool(value)
+				if err != nil {
+					return fmt.Errorf("invalid value %q for %q: expected true or false", value, key)
+				}
+				f.SetBool(b)
+				return nil
 			}
 			return fmt.Errorf("unsupported field type for %q", key)
 		}
@@ -158,12 +275,17 @@ func (c *Config) Get(key string) (string, error) {
 
 	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
-		tag := field.Tag.Get("yaml")
-		if tag == key {
+		if yamlKey(field) == key {
 			f := v.Field(i)
 			if f.Kind() == reflect.String {
 				return f.String(), nil
 			}
+			if items, ok := f.Interface().([]string); ok {
+				if items == nil {
+					items = listDefaults[key]
+				}
+				return strings.Join(items, ","), nil
+			}
 			return fmt.Sprintf("%v", f.Interface()), nil
 		}
 	}
@@ -171,43 +293,90 @@ func (c *Config) Get(key string) (string, error) {
 }
 
 func (c *Config) Validate() error {
-	if c.ActiveAPIKey() == "" {
-		switch c.LLMProvider {
-		case "openai":
-			return fmt.Errorf("OPENAI_API_KEY is required for OpenAI provider (set via config or env var)")
-		case "anthropic":
-			return fmt.Errorf("ANTHROPIC_API_KEY is required for Anthropic provider (set via config or env var)")
-		default:
-			return fmt.Errorf("GEMINI_API_KEY is required for Gemini provider (set via config or env var)")
-		}
+	p := llm.ProviderFor(c.LLMProvider)
+	if err := p.CheckCredentials(c.ActiveAPIKey()); err != nil {
+		return err
+	}
+	if c.LLMBaseURL == "" && p.DefaultBaseURL() == "" {
+		return fmt.Errorf("llm_base_url is required for %s provider", p.Label())
 	}
 	return nil
 }
 
 // ActiveAPIKey returns the API key for the currently configured LLM provider.
 func (c *Config) ActiveAPIKey() string {
-	switch c.LLMProvider {
-	case "openai":
-		return c.OpenAIAPIKey
-	case "anthropic":
-		return c.AnthropicAPIKey
-	default:
-		return c.GeminiAPIKey
+	key, _ := c.Get(llm.ProviderFor(c.LLMProvider).APIKeyConfigKey())
+	return key
+}
+
+// SetActiveAPIKey stores the API key for the currently configured LLM provider
+// without saving.
+func (c *Config) SetActiveAPIKey(value string) error {
+	return c.setField(llm.ProviderFor(c.LLMProvider).APIKeyConfigKey(), value)
+}
+
+// LLMOptions returns the client options for the configured LLM provider.
+func (c *Config) LLMOptions() llm.Options {
+	return llm.Options{
+		Provider:          c.LLMProvider,
+		APIKey:            c.ActiveAPIKey(),
+		Model:             c.DefaultModel,
+		BaseURL:           c.LLMBaseURL,
+		MaxRetries:        c.LLMMaxRetries,
+		Timeout:           time.Duration(c.LLMTimeoutSeconds) * time.Second,
+		RequestsPerMinute: c.LLMRequestsPerMinute,
+	}
+}
+
+// LLMCacheTTL returns how long cached LLM responses stay valid. Zero means
+// caching is disabled.
+func (c *Config) LLMCacheTTL() time.Duration {
+	if c.LLMCacheTTLHours <= 0 {
+		return 0
 	}
+	return time.Duration(c.LLMCacheTTLHours) * time.Hour
+}
+
+// EstimateCost returns the cost in US dollars of usage with the given model.
+// Local models are free unless priced in llm_prices. The second result is
+// false if the model has no known price.
+func (c *Config) EstimateCost(provider, model string, u llm.Usage) (float64, bool) {
+	if price, ok := c.LLMPrices[model]; ok {
+		return price.Cost(u), true
+	}
+	if provider == llm.ProviderLocal {
+		return 0, true
+	}
+	price, ok := llm.DefaultPrices[model]
+	if !ok {
+		return 0, false
+	}
+	return price.Cost(u), true
+}
+
+// HasLLM reports whether the LLM provider is usable: either it has an API key
+// or it is a local server that does not need one.
+func (c *Config) HasLLM() bool {
+	return c.Validate() == nil
 }
 
 func ValidConfigKeys() []string {
 	t := reflect.TypeOf(Config{})
 	keys := make([]string, 0, t.NumField())
 	for i := 0; i < t.NumField(); i++ {
-		tag := t.Field(i).Tag.Get("yaml")
-		if tag != "" && tag != "-" {
-			keys = append(keys, tag)
+		field := t.Field(i)
+		// Repos and prices are edited with their own commands
+		if key := yamlKey(field); key != "" && key != "-" && field.Type.Kind() != reflect.Map && !isStructSlice(field.Type) {
+			keys = append(keys, key)
 		}
 	}
 	return keys
 }
 
+func isStructSlice(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Struct
+}
+
 func ValidConfigKeysStr() string {
 	return strings.Join(ValidConfigKeys(), ", ")
 }
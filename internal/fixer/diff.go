@@ -1,115 +1,214 @@
 package fixer
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
 )
 
-// ApplyDiff attempts to apply a diff to original content.
-// Handles unified diff format, full replacement code, and markdown blocks.
-func ApplyDiff(original, fixDiff string) string {
-	cleaned := strings.TrimSpace(fixDiff)
+var codeBlockRe = regexp.MustCompile(`(?s)` + "```(?:\\w+)?\\s*\n(.*?)\n```")
 
-	// Extract from markdown code blocks
-	codeBlockRe := regexp.MustCompile(`(?s)` + "```(?:\\w+)?\\s*\n(.*?)\n```")
+// stripCodeFence extracts the body of a markdown code block if present.
+func stripCodeFence(text string) string {
+	cleaned := strings.TrimSpace(text)
 	if m := codeBlockRe.FindStringSubmatch(cleaned); len(m) > 1 {
 		cleaned = strings.TrimSpace(m[1])
 	}
+	return cleaned
+}
 
-	// Check if it's unified diff format
-	if strings.Contains(cleaned, "@@") || strings.Count(cleaned, "\n+") > 2 || strings.Count(cleaned, "\n-") > 2 {
-		result, err := applyUnifiedDiff(original, cleaned)
-		if err == nil {
-			return result
-		}
-	}
+var hunkHeaderRe = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+type hunk struct {
+	oldStart int
+	lines    []string // body lines including their ' ', '-' or '+' prefix
+}
+
+// NormalizeDiff checks that every hunk of a unified diff matches original
+// and rewrites the hunk headers with the line numbers where each hunk's
+// context and removed lines actually occur. LLM-generated diffs are often
+// off by a few lines; a hunk is accepted at the nearest position where its
+// lines match, ignoring surrounding whitespace. An error is returned if any
+// hunk cannot be located.
+func NormalizeDiff(original, diffText string) (string, error) {
+	var header []string
+	var hunks []*hunk
+	var cur *hunk
 
-	// Check if it looks like replacement code (not description text)
-	if !strings.HasPrefix(cleaned, "---") && !strings.HasPrefix(cleaned, "+++") && !strings.HasPrefix(cleaned, "diff ") && len(cleaned) > 50 {
-		lines := strings.Split(cleaned, "\n")
-		maxLines := 10
-		if len(lines) < maxLines {
-			maxLines = len(lines)
+	for _, line := range strings.Split(stripCodeFence(diffText), "\n") {
+		if m := hunkHeaderRe.FindStringSubmatch(line); m != nil {
+			start := 0
+			fmt_sscanf(m[1], &start)
+			cur = &hunk{oldStart: start}
+			hunks = append(hunks, cur)
+			continue
 		}
-		totalWords := 0
-		for _, line := range lines[:maxLines] {
-			totalWords += len(strings.Fields(line))
+		if cur == nil {
+			if strings.HasPrefix(line, "---") || strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "diff ") {
+				header = append(header, line)
+			}
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, `\`):
+			// "\ No newline at end of file"
+		case line == "":
+			cur.lines = append(cur.lines, " ")
+		case line[0] == ' ' || line[0] == '-' || line[0] == '+':
+			cur.lines = append(cur.lines, line)
+		default:
+			return "", fmt.Errorf("unexpected line in hunk: %q", line)
 		}
-		avgWords := float64(totalWords) / float64(maxLines)
+	}
+	if len(hunks) == 0 {
+		return "", fmt.Errorf("no diff hunks found")
+	}
+
+	var origLines []string
+	if original != "" {
+		origLines = strings.Split(original, "\n")
+	}
 
-		specialChars := 0
-		end := 500
-		if len(cleaned) < end {
-			end = len(cleaned)
+	var out strings.Builder
+	for _, h := range header {
+		out.WriteString(h + "\n")
+	}
+
+	searchFrom, delta := 0, 0
+	for i, h := range hunks {
+		var old []string
+		newCount := 0
+		for _, l := range h.lines {
+			if l[0] != '+' {
+				old = append(old, l[1:])
+			}
+			if l[0] != '-' {
+				newCount++
+			}
 		}
-		for _, c := range cleaned[:end] {
-			switch c {
-			case '{', '}', '[', ']', '(', ')', ';', '=', '<', '>', ':':
-				specialChars++
+
+		var start int // 0-based index in origLines
+		if len(old) == 0 {
+			// Pure insertion after line oldStart
+			start = h.oldStart
+			if start > len(origLines) || start < searchFrom {
+				return "", fmt.Errorf("hunk %d inserts after line %d, outside the file", i+1, h.oldStart)
+			}
+		} else {
+			start = locateLines(origLines, old, h.oldStart-1, searchFrom)
+			if start < 0 {
+				return "", fmt.Errorf("hunk %d does not match the file contents near line %d", i+1, h.oldStart)
 			}
 		}
 
-		if avgWords < 8 || specialChars > 20 {
-			return cleaned
+		oldStart, newStart := start+1, start+1+delta
+		if len(old) == 0 {
+			oldStart, newStart = start, start+delta+1
+		}
+		fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n", oldStart, len(old), newStart, newCount)
+		for _, l := range h.lines {
+			out.WriteString(l + "\n")
 		}
+
+		searchFrom = start + len(old)
+		delta += newCount - len(old)
 	}
 
-	// Return original if unparseable
-	return original
+	return strings.TrimRight(out.String(), "\n"), nil
 }
 
-func applyUnifiedDiff(original, diffText string) (string, error) {
-	resultLines := strings.Split(original, "\n")
+// locateLines finds the index at which want occurs in lines, preferring the
+// position closest to hint and never before min. Lines are compared with
+// surrounding whitespace trimmed. Returns -1 if there is no match.
+func locateLines(lines, want []string, hint, min int) int {
+	matches := func(at int) bool {
+		if at < min || at+len(want) > len(lines) {
+			return false
+		}
+		for j, w := range want {
+			if strings.TrimSpace(lines[at+j]) != strings.TrimSpace(w) {
+				return false
+			}
+		}
+		return true
+	}
 
-	hunkPattern := regexp.MustCompile(`@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
-	lines := strings.Split(diffText, "\n")
-	offset := 0
+	if hint < min {
+		hint = min
+	}
+	for d := 0; d <= len(lines); d++ {
+		if matches(hint - d) {
+			return hint - d
+		}
+		if d > 0 && matches(hint+d) {
+			return hint + d
+		}
+	}
+	return -1
+}
 
-	i := 0
-	for i < len(lines) {
-		line := lines[i]
-
-		match := hunkPattern.FindStringSubmatch(line)
-		if match != nil {
-			oldStart := 0
-			fmt_sscanf(match[1], &oldStart)
-			oldStart = oldStart - 1 + offset
-			i++
+// ApplyPatch applies a unified diff to original. The diff is first checked
+// with NormalizeDiff, and an error is returned if any hunk does not match the
+// file, so a diff that does not apply never produces a partial result.
+// Context lines keep the file's own text, and added lines get the file's
+// line endings.
+func ApplyPatch(original, diffText string) (string, error) {
+	normalized, err := NormalizeDiff(original, diffText)
+	if err != nil {
+		return "", err
+	}
 
-			for i < len(lines) && !strings.HasPrefix(lines[i], "@@") {
-				hunkLine := lines[i]
-				if strings.HasPrefix(hunkLine, "-") {
-					if oldStart >= 0 && oldStart < len(resultLines) {
-						if strings.TrimSpace(resultLines[oldStart]) == strings.TrimSpace(hunkLine[1:]) {
-							resultLines = append(resultLines[:oldStart], resultLines[oldStart+1:]...)
-							offset--
-						} else {
-							oldStart++
-						}
-					} else {
-						oldStart++
-					}
-				} else if strings.HasPrefix(hunkLine, "+") {
-					newLine := hunkLine[1:]
-					if oldStart > len(resultLines) {
-						resultLines = append(resultLines, newLine)
-					} else {
-						resultLines = append(resultLines[:oldStart+1], resultLines[oldStart:]...)
-						resultLines[oldStart] = newLine
-					}
-					oldStart++
-					offset++
-				} else if strings.HasPrefix(hunkLine, " ") || strings.TrimSpace(hunkLine) == "" {
-					oldStart++
-				}
-				i++
+	var lines []string
+	if original != "" {
+		lines = strings.Split(original, "\n")
+	}
+	// Lines are split at "\n", so on a CRLF file each keeps its "\r"
+	crlf := strings.Contains(original, "\r\n")
+	var out []string
+	next := 0 // index of the first original line not yet copied
+	inHunk := false
+	for _, line := range strings.Split(normalized, "\n") {
+		if m := hunkHeaderRe.FindStringSubmatch(line); m != nil {
+			oldStart, oldCount := 0, 1
+			fmt_sscanf(m[1], &oldStart)
+			if m[2] != "" {
+				fmt_sscanf(m[2], &oldCount)
 			}
-		} else {
-			i++
+			// A zero-length old range inserts after line oldStart
+			at := oldStart
+			if oldCount > 0 {
+				at--
+			}
+			if at < next || at > len(lines) {
+				return "", fmt.Errorf("hunk at line %d is out of order", oldStart)
+			}
+			out = append(out, lines[next:at]...)
+			next = at
+			inHunk = true
+			continue
+		}
+		if !inHunk || line == "" {
+			continue
+		}
+		switch line[0] {
+		case ' ', '-':
+			if next >= len(lines) || strings.TrimSpace(lines[next]) != strings.TrimSpace(line[1:]) {
+				return "", fmt.Errorf("line %d does not match the diff", next+1)
+			}
+			if line[0] == ' ' {
+				out = append(out, lines[next])
+			}
+			next++
+		case '+':
+			added := strings.TrimSuffix(line[1:], "\r")
+			if crlf {
+				added += "\r"
+			}
+			out = append(out, added)
 		}
 	}
-
-	return strings.Join(resultLines, "\n"), nil
+	out = append(out, lines[next:]...)
+	return strings.Join(out, "\n"), nil
 }
 
 func fmt_sscanf(s string, v *int) {
@@ -121,3 +220,169 @@ func fmt_sscanf(s string, v *int) {
 	}
 	*v = n
 }
+
+// UnifiedDiff renders the line-level changes between original and updated
+// as a unified diff with three lines of context. It returns an empty string
+// when the two are identical.
+func UnifiedDiff(path, original, updated string) string {
+	if original == updated {
+		return ""
+	}
+	a := splitLines(original)
+	b := splitLines(updated)
+	ops := diffLines(a, b)
+
+	const context = 3
+	var out strings.Builder
+	out.WriteString("--- a/" + path + "\n")
+	out.WriteString("+++ b/" + path + "\n")
+
+	i := 0
+	for i < len(ops) {
+		// Find the next change
+		for i < len(ops) && ops[i].kind == ' ' {
+			i++
+		}
+		if i >= len(ops) {
+			break
+		}
+
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		// Extend the hunk until there are more than 2*context unchanged lines in a row
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].kind == ' ' {
+				run++
+			}
+			if run == len(ops) || run-end > 2*context {
+				end += context
+				if end > len(ops) {
+					end = len(ops)
+				}
+				break
+			}
+			end = run
+		}
+
+		oldStart, newStart := ops[start].oldLine, ops[start].newLine
+		oldCount, newCount := 0, 0
+		var body strings.Builder
+		for _, op := range ops[start:end] {
+			switch op.kind {
+			case ' ':
+				oldCount++
+				newCount++
+			case '-':
+				oldCount++
+			case '+':
+				newCount++
+			}
+			body.WriteString(string(op.kind) + op.text + "\n")
+		}
+		// A range with no lines names the line before it, so a new file is -0,0
+		if oldCount == 0 {
+			oldStart--
+		}
+		if newCount == 0 {
+			newStart--
+		}
+		out.WriteString(fmt.Sprintf("@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount))
+		out.WriteString(body.String())
+		i = end
+	}
+
+	return strings.TrimRight(out.String(), "\n")
+}
+
+// splitLines splits s into lines without the empty element that
+// strings.Split leaves after a final newline.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+type diffOp struct {
+	kind    byte // ' ', '-' or '+'
+	text    string
+	oldLine int // 1-based line in the original at or before this op
+	newLine int // 1-based line in the updated text at or before this op
+}
+
+// diffLines computes a minimal edit script between a and b using the
+// longest common subsequence of the lines between their common prefix
+// and suffix.
+func diffLines(a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	midA := a[prefix : len(a)-suffix]
+	midB := b[prefix : len(b)-suffix]
+
+	// lcs[i][j] is the LCS length of midA[i:] and midB[j:]
+	lcs := make([][]int, len(midA)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(midB)+1)
+	}
+	for i := len(midA) - 1; i >= 0; i-- {
+		for j := len(midB) - 1; j >= 0; j-- {
+			if midA[i] == midB[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var ops []diffOp
+	oldLine, newLine := 1, 1
+	emit := func(kind byte, text string) {
+		ops = append(ops, diffOp{kind: kind, text: text, oldLine: oldLine, newLine: newLine})
+		if kind != '+' {
+			oldLine++
+		}
+		if kind != '-' {
+			newLine++
+		}
+	}
+
+	for _, line := range a[:prefix] {
+		emit(' ', line)
+	}
+	i, j := 0, 0
+	for i < len(midA) || j < len(midB) {
+		switch {
+		case i < len(midA) && j < len(midB) && midA[i] == midB[j]:
+			emit(' ', midA[i])
+			i++
+			j++
+		case i < len(midA) && (j == len(midB) || lcs[i+1][j] >= lcs[i][j+1]):
+			emit('-', midA[i])
+			i++
+		default:
+			emit('+', midB[j])
+			j++
+		}
+	}
+	for _, line := range a[len(a)-suffix:] {
+		emit(' ', line)
+	}
+	return ops
+}
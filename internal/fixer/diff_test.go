@@ -0,0 +1,159 @@
+package fixer
+
+import "testing"
+
+func TestApplyPatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		original string
+		diff     string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "replace line",
+			original: "a\nb\nc\nd\ne\n",
+			diff:     "--- a/x\n+++ b/x\n@@ -3,2 +3,2 @@\n c\n-d\n+D\n",
+			want:     "a\nb\nc\nD\ne\n",
+		},
+		{
+			name:     "hunk off by some lines",
+			original: "a\nb\nc\nd\ne\n",
+			diff:     "@@ -1,2 +1,2 @@\n c\n-d\n+D\n",
+			want:     "a\nb\nc\nD\ne\n",
+		},
+		{
+			name:     "insert and replace",
+			original: "a\nb\nc\nd\ne\n",
+			diff:     "@@ -1,0 +2,1 @@\n+ins\n@@ -4,1 +5,1 @@\n-d\n+DD\n",
+			want:     "a\nins\nb\nc\nDD\ne\n",
+		},
+		{
+			name:     "context keeps file indentation",
+			original: "func f() {\n\treturn 1\n}\n",
+			diff:     "@@ -1,3 +1,3 @@\n func f() {\n-    return 1\n+\treturn 2\n }\n",
+			want:     "func f() {\n\treturn 2\n}\n",
+		},
+		{
+			name:     "CRLF file",
+			original: "a\r\nb\r\nc\r\n",
+			diff:     "@@ -2,1 +2,2 @@\n-b\n+B\n+B2\n",
+			want:     "a\r\nB\r\nB2\r\nc\r\n",
+		},
+		{
+			name:     "CRLF diff on LF file",
+			original: "a\nb\nc\n",
+			diff:     "@@ -2,1 +2,1 @@\r\n-b\r\n+B\r\n",
+			want:     "a\nB\nc\n",
+		},
+		{
+			name:     "new file",
+			original: "",
+			diff:     "@@ -0,0 +1,2 @@\n+new\n+file\n",
+			want:     "new\nfile",
+		},
+		{
+			name:     "removed line not in file",
+			original: "a\nb\nc\n",
+			diff:     "@@ -1,1 +1,1 @@\n-zzz\n+y\n",
+			wantErr:  true,
+		},
+		{
+			name:     "diff with lines to remove on empty file",
+			original: "",
+			diff:     "@@ -1,1 +1,1 @@\n-a\n+b\n",
+			wantErr:  true,
+		},
+		{
+			name:     "replacement code instead of a diff",
+			original: "a\nb\n",
+			diff:     "func main() { fmt.Println(\"replaced\"); os.Exit(1); return; x := []int{1, 2, 3} }",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ApplyPatch(tt.original, tt.diff)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ApplyPatch = %q, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ApplyPatch: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ApplyPatch = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		original string
+		updated  string
+		want     string
+	}{
+		{
+			name:     "identical",
+			original: "a\nb\n",
+			updated:  "a\nb\n",
+			want:     "",
+		},
+		{
+			name:     "change in short file",
+			original: "a\nb\nc\nd\ne\n",
+			updated:  "a\nb\nC\nC2\nd\ne\n",
+			want:     "--- a/f.go\n+++ b/f.go\n@@ -1,5 +1,6 @@\n a\n b\n-c\n+C\n+C2\n d\n e",
+		},
+		{
+			name:     "append to file",
+			original: "a\nb\n",
+			updated:  "a\nb\nc\n",
+			want:     "--- a/f.go\n+++ b/f.go\n@@ -1,2 +1,3 @@\n a\n b\n+c",
+		},
+		{
+			name:     "new file",
+			original: "",
+			updated:  "x\ny\n",
+			want:     "--- a/f.go\n+++ b/f.go\n@@ -0,0 +1,2 @@\n+x\n+y",
+		},
+		{
+			name:     "emptied file",
+			original: "x\ny\n",
+			updated:  "",
+			want:     "--- a/f.go\n+++ b/f.go\n@@ -1,2 +0,0 @@\n-x\n-y",
+		},
+		{
+			name:     "separate hunks",
+			original: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			updated:  "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ntwelve\n",
+			want: "--- a/f.go\n+++ b/f.go\n" +
+				"@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n" +
+				"@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+twelve",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := UnifiedDiff("f.go", tt.original, tt.updated)
+			if got != tt.want {
+				t.Errorf("UnifiedDiff =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnifiedDiffAppliesBack(t *testing.T) {
+	original := "package main\n\nfunc main() {\n\tprintln(\"a\")\n}\n"
+	updated := "package main\n\nimport \"os\"\n\nfunc main() {\n\tos.Exit(0)\n}\n"
+	got, err := ApplyPatch(original, UnifiedDiff("main.go", original, updated))
+	if err != nil {
+		t.Fatalf("ApplyPatch: %v", err)
+	}
+	if got != updated {
+		t.Errorf("ApplyPatch(UnifiedDiff) = %q, want %q", got, updated)
+	}
+}
@@ -3,8 +3,16 @@ package fixer
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/nerifect/nerifect-cli/internal/llm"
+	"github.com/nerifect/nerifect-cli/internal/store"
+)
+
+// Lines of surrounding code sent on each side of the violation.
+const (
+	contextLines   = 40
+	maxWindowLines = 300
 )
 
 // FixResult holds the output of LLM fix generation.
@@ -23,30 +31,112 @@ func NewFixer(client *llm.Client) *Fixer {
 	return &Fixer{client: client}
 }
 
-// GenerateFix produces a fix for a given violation.
-func (f *Fixer) GenerateFix(ctx context.Context, ruleDesc, filePath, severity, violationDesc, fileContent string) (*FixResult, error) {
-	prompt := llm.BuildFixPrompt(ruleDesc, filePath, severity, violationDesc, fileContent)
+// GenerateFix produces a fix for a violation. fileContent is the full
+// content of the violating file as scanned; an empty string means the file
+// does not exist and the fix should create it. The returned diff has been
+// checked against fileContent and its hunk headers corrected.
+func (f *Fixer) GenerateFix(ctx context.Context, v *store.Violation, fileContent string) (*FixResult, error) {
+	first, last := violationLines(v, fileContent)
+	window, contentRange := numberedWindow(fileContent, first, last)
 
-	responseText, err := f.client.GenerateContent(ctx, prompt)
-	if err != nil {
-		return nil, fmt.Errorf("fix generation failed: %w", err)
+	location := "unknown"
+	if first > 0 {
+		location = fmt.Sprintf("lines %d-%d", first, last)
 	}
 
+	prompt := llm.BuildFixPrompt(v.RuleID, v.FilePath, string(v.Severity), v.Description, location, contentRange, window)
+
 	var result FixResult
-	if err := llm.ParseJSONResponse(responseText, &result); err != nil {
-		// If JSON parsing fails, use the raw text as description
-		return &FixResult{
-			FixDescription: truncate(responseText, 200),
-			Confidence:     0.5,
-		}, nil
+	if err := f.client.GenerateJSON(ctx, prompt, llm.FixSchema, &result); err != nil {
+		return nil, fmt.Errorf("fix generation failed: %w", err)
+	}
+
+	if strings.TrimSpace(result.FixDiff) != "" {
+		normalized, err := NormalizeDiff(fileContent, result.FixDiff)
+		if err != nil {
+			return nil, fmt.Errorf("generated diff does not match %s: %w", v.FilePath, err)
+		}
+		result.FixDiff = normalized
 	}
 
 	return &result, nil
 }
 
-func truncate(s string, max int) string {
-	if len(s) <= max {
-		return s
+// RecordUsage stores the usage of the LLM calls made since the last fix was
+// recorded. Pass 0 when fix generation failed and no fix was saved.
+func (f *Fixer) RecordUsage(fixID int64) {
+	for _, c := range f.client.TakeCalls() {
+		store.RecordLLMUsage(&store.LLMUsage{
+			Provider:     c.Provider,
+			Model:        c.Model,
+			Operation:    store.UsageOperationFix,
+			InputTokens:  c.Usage.InputTokens,
+			OutputTokens: c.Usage.OutputTokens,
+			FixID:        fixID,
+		})
+	}
+}
+
+// violationLines returns the 1-based line range of the violation in content.
+// Violations without line numbers are located by their code snippet.
+func violationLines(v *store.Violation, content string) (int, int) {
+	if v.LineStart > 0 {
+		end := v.LineEnd
+		if end < v.LineStart {
+			end = v.LineStart
+		}
+		return v.LineStart, end
+	}
+
+	snippet := strings.TrimSpace(v.CodeSnippet)
+	if snippet == "" || content == "" {
+		return 0, 0
+	}
+	firstLine := strings.TrimSpace(strings.SplitN(snippet, "\n", 2)[0])
+	if firstLine == "" {
+		return 0, 0
+	}
+	for i, line := range strings.Split(content, "\n") {
+		if strings.Contains(line, firstLine) {
+			n := strings.Count(snippet, "\n")
+			return i + 1, i + 1 + n
+		}
+	}
+	return 0, 0
+}
+
+// numberedWindow returns the lines of content around first..last, each
+// prefixed with its line number, and a description of the range covered.
+func numberedWindow(content string, first, last int) (string, string) {
+	if content == "" {
+		return "", "file does not exist yet"
+	}
+
+	lines := strings.Split(content, "\n")
+	start, end := 1, len(lines)
+	if first > 0 {
+		start = first - contextLines
+		end = last + contextLines
+	}
+	if start < 1 {
+		start = 1
+	}
+	if end > len(lines) {
+		end = len(lines)
+	}
+	if end-start+1 > maxWindowLines {
+		end = start + maxWindowLines - 1
+	}
+
+	var b strings.Builder
+	width := len(fmt.Sprint(end))
+	for n := start; n <= end; n++ {
+		fmt.Fprintf(&b, "%*d| %s\n", width, n, lines[n-1])
+	}
+
+	desc := fmt.Sprintf("lines %d-%d of %d", start, end, len(lines))
+	if start == 1 && end == len(lines) {
+		desc = fmt.Sprintf("entire file, %d lines", len(lines))
 	}
-	return s[:max]
+	return b.String(), desc
 }
@@ -5,7 +5,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 )
 
@@ -16,9 +15,9 @@ const (
 )
 
 var anthropicValidModels = map[string]bool{
-	"claude-sonnet-4-20250514":    true,
-	"claude-3-5-haiku-20241022":   true,
-	"claude-opus-4-20250514":      true,
+	"claude-sonnet-4-20250514":  true,
+	"claude-3-5-haiku-20241022": true,
+	"claude-opus-4-20250514":    true,
 }
 
 var anthropicDeprecatedModels = map[string]string{
@@ -27,83 +26,124 @@ var anthropicDeprecatedModels = map[string]string{
 	"claude-3-haiku-20240307":  "claude-3-5-haiku-20241022",
 }
 
-func validateAnthropicModel(model string) string {
-	if model == "" {
-		return AnthropicDefaultModel
-	}
-	if anthropicValidModels[model] {
-		return model
-	}
-	if replacement, ok := anthropicDeprecatedModels[model]; ok {
-		return replacement
-	}
-	return AnthropicDefaultModel
+func init() {
+	Register(anthropicProvider{modelCatalog{
+		defaultModel: AnthropicDefaultModel,
+		valid:        anthropicValidModels,
+		deprecated:   anthropicDeprecatedModels,
+	}})
+}
+
+type anthropicProvider struct {
+	modelCatalog
 }
 
-func (c *Client) generateAnthropic(ctx context.Context, prompt string) (string, error) {
-	url := fmt.Sprintf("%s/messages", AnthropicBaseURL)
+func (anthropicProvider) Name() string            { return ProviderAnthropic }
+func (anthropicProvider) Label() string           { return "Anthropic" }
+func (anthropicProvider) DefaultBaseURL() string  { return AnthropicBaseURL }
+func (anthropicProvider) APIKeyEnvVar() string    { return "ANTHROPIC_API_KEY" }
+func (anthropicProvider) APIKeyConfigKey() string { return "anthropic_api_key" }
+
+func (p anthropicProvider) CheckCredentials(apiKey string) error {
+	return requireAPIKey(p, apiKey)
+}
+
+func (anthropicProvider) Generate(ctx context.Context, r *Request) (*Response, error) {
+	url := fmt.Sprintf("%s/messages", r.BaseURL)
 
 	reqBody := anthropicRequest{
-		Model: c.model,
+		Model: r.Model,
 		Messages: []anthropicMessage{
-			{Role: "user", Content: prompt},
+			{Role: "user", Content: r.Prompt},
 		},
 		MaxTokens: 8192,
 	}
+	if r.Schema != nil {
+		// Forcing a single tool call makes the model emit its input as JSON
+		// matching the schema.
+		reqBody.Tools = []anthropicTool{{
+			Name:        r.Schema.Name,
+			Description: r.Schema.Description,
+			InputSchema: r.Schema.Definition,
+		}}
+		reqBody.ToolChoice = &anthropicToolChoice{Type: "tool", Name: r.Schema.Name}
+	}
 
 	bodyBytes, err := json.Marshal(reqBody)
 	if err != nil {
-		return "", fmt.Errorf("marshaling request: %w", err)
+		return nil, fmt.Errorf("marshaling request: %w", err)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(bodyBytes))
 	if err != nil {
-		return "", fmt.Errorf("creating request: %w", err)
+		return nil, fmt.Errorf("creating request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("x-api-key", c.apiKey)
+	req.Header.Set("x-api-key", r.APIKey)
 	req.Header.Set("anthropic-version", AnthropicAPIVersion)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := r.HTTPClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("calling Anthropic API: %w", err)
+		return nil, fmt.Errorf("calling Anthropic API: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("Anthropic API error %d: %s", resp.StatusCode, string(body))
+		return nil, newAPIError("Anthropic", resp)
 	}
 
 	var anthropicResp anthropicResponse
 	if err := json.NewDecoder(resp.Body).Decode(&anthropicResp); err != nil {
-		return "", fmt.Errorf("decoding response: %w", err)
+		return nil, fmt.Errorf("decoding response: %w", err)
 	}
 
 	if len(anthropicResp.Content) == 0 {
-		return "", fmt.Errorf("empty response from Anthropic")
+		return nil, fmt.Errorf("empty response from Anthropic")
 	}
 
-	// Concatenate all text blocks
+	usage := Usage{
+		InputTokens:  anthropicResp.Usage.InputTokens,
+		OutputTokens: anthropicResp.Usage.OutputTokens,
+	}
+
+	// Concatenate all text blocks, or use the forced tool call's input
 	var result string
 	for _, block := range anthropicResp.Content {
-		if block.Type == "text" {
+		switch block.Type {
+		case "text":
 			result += block.Text
+		case "tool_use":
+			if r.Schema != nil {
+				return &Response{Text: string(block.Input), Usage: usage}, nil
+			}
 		}
 	}
 
 	if result == "" {
-		return "", fmt.Errorf("no text content in Anthropic response")
+		return nil, fmt.Errorf("no text content in Anthropic response")
 	}
 
-	return result, nil
+	return &Response{Text: result, Usage: usage}, nil
 }
 
 // Anthropic API types
 type anthropicRequest struct {
-	Model     string             `json:"model"`
-	Messages  []anthropicMessage `json:"messages"`
-	MaxTokens int                `json:"max_tokens"`
+	Model      string               `json:"model"`
+	Messages   []anthropicMessage   `json:"messages"`
+	MaxTokens  int                  `json:"max_tokens"`
+	Tools      []anthropicTool      `json:"tools,omitempty"`
+	ToolChoice *anthropicToolChoice `json:"tool_choice,omitempty"`
+}
+
+type anthropicTool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	InputSchema map[string]interface{} `json:"input_schema"`
+}
+
+type anthropicToolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
 }
 
 type anthropicMessage struct {
@@ -113,9 +153,14 @@ type anthropicMessage struct {
 
 type anthropicResponse struct {
 	Content []anthropicContentBlock `json:"content"`
+	Usage   struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
 }
 
 type anthropicContentBlock struct {
-	Type string `json:"type"`
-	Text string `json:"text,omitempty"`
+	Type  string          `json:"type"`
+	Text  string          `json:"text,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
 }
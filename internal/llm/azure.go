@@ -0,0 +1,54 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+const (
+	AzureOpenAIAPIVersion   = "2024-10-21"
+	AzureOpenAIDefaultModel = "gpt-4o"
+)
+
+func init() {
+	// Azure addresses models by deployment name, which is chosen by the
+	// operator, so any name is accepted.
+	Register(azureOpenAIProvider{modelCatalog{
+		defaultModel: AzureOpenAIDefaultModel,
+		freeForm:     true,
+	}})
+}
+
+type azureOpenAIProvider struct {
+	modelCatalog
+}
+
+func (azureOpenAIProvider) Name() string            { return ProviderAzureOpenAI }
+func (azureOpenAIProvider) Label() string           { return "Azure OpenAI" }
+func (azureOpenAIProvider) APIKeyEnvVar() string    { return "AZURE_OPENAI_API_KEY" }
+func (azureOpenAIProvider) APIKeyConfigKey() string { return "azure_openai_api_key" }
+
+// DefaultBaseURL reads the resource endpoint, e.g.
+// https://my-resource.openai.azure.com, from AZURE_OPENAI_ENDPOINT.
+func (azureOpenAIProvider) DefaultBaseURL() string {
+	return os.Getenv("AZURE_OPENAI_ENDPOINT")
+}
+
+func (p azureOpenAIProvider) CheckCredentials(apiKey string) error {
+	return requireAPIKey(p, apiKey)
+}
+
+func (azureOpenAIProvider) Generate(ctx context.Context, r *Request) (*Response, error) {
+	if r.BaseURL == "" {
+		return nil, fmt.Errorf("Azure OpenAI endpoint is not set (set llm_base_url or AZURE_OPENAI_ENDPOINT)")
+	}
+	endpoint := fmt.Sprintf("%s/openai/deployments/%s/chat/completions?api-version=%s",
+		r.BaseURL, url.PathEscape(r.Model), AzureOpenAIAPIVersion)
+
+	return chatCompletion(ctx, r, endpoint, "Azure OpenAI", true, func(req *http.Request) {
+		req.Header.Set("api-key", r.APIKey)
+	})
+}
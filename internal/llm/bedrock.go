@@ -0,0 +1,311 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	BedrockDefaultModel  = "anthropic.claude-3-5-sonnet-20240620-v1:0"
+	BedrockDefaultRegion = "us-east-1"
+)
+
+var bedrockKnownModels = map[string]bool{
+	"anthropic.claude-3-5-sonnet-20240620-v1:0": true,
+	"anthropic.claude-3-5-haiku-20241022-v1:0":  true,
+	"amazon.nova-pro-v1:0":                      true,
+	"amazon.nova-lite-v1:0":                     true,
+	"meta.llama3-1-70b-instruct-v1:0":           true,
+	"mistral.mistral-large-2407-v1:0":           true,
+}
+
+func init() {
+	// Bedrock model IDs include region-specific inference profiles, so
+	// unknown IDs are passed through rather than replaced.
+	Register(bedrockProvider{modelCatalog{
+		defaultModel: BedrockDefaultModel,
+		valid:        bedrockKnownModels,
+		freeForm:     true,
+	}})
+}
+
+// bedrockProvider calls the Bedrock Converse API. It authenticates with a
+// Bedrock API key when one is configured and otherwise signs requests with
+// the AWS credentials from the environment.
+type bedrockProvider struct {
+	modelCatalog
+}
+
+func (bedrockProvider) Name() string            { return ProviderBedrock }
+func (bedrockProvider) Label() string           { return "AWS Bedrock" }
+func (bedrockProvider) APIKeyEnvVar() string    { return "AWS_BEARER_TOKEN_BEDROCK" }
+func (bedrockProvider) APIKeyConfigKey() string { return "bedrock_api_key" }
+
+func (bedrockProvider) DefaultBaseURL() string {
+	return fmt.Sprintf("https://bedrock-runtime.%s.amazonaws.com", bedrockRegion())
+}
+
+func (p bedrockProvider) CheckCredentials(apiKey string) error {
+	if apiKey != "" {
+		return nil
+	}
+	if _, ok := awsCredentialsFromEnv(); ok {
+		return nil
+	}
+	return fmt.Errorf("%s or AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are required for %s provider", p.APIKeyEnvVar(), p.Label())
+}
+
+func (bedrockProvider) Generate(ctx context.Context, r *Request) (*Response, error) {
+	// Model IDs contain ':', which Bedrock expects percent-encoded
+	modelPath := strings.ReplaceAll(url.PathEscape(r.Model), ":", "%3A")
+	endpoint := fmt.Sprintf("%s/model/%s/converse", r.BaseURL, modelPath)
+
+	reqBody := bedrockRequest{
+		Messages: []bedrockMessage{
+			{Role: "user", Content: []bedrockContentBlock{{Text: r.Prompt}}},
+		},
+		InferenceConfig: bedrockInferenceConfig{
+			MaxTokens:   8192,
+			Temperature: 0.2,
+		},
+	}
+	if r.Schema != nil {
+		// Forcing a single tool call makes the model emit its input as JSON
+		// matching the schema.
+		reqBody.ToolConfig = &bedrockToolConfig{
+			Tools: []bedrockTool{{ToolSpec: bedrockToolSpec{
+				Name:        r.Schema.Name,
+				Description: r.Schema.Description,
+				InputSchema: bedrockInputSchema{JSON: r.Schema.Definition},
+			}}},
+			ToolChoice: map[string]interface{}{"tool": map[string]string{"name": r.Schema.Name}},
+		}
+	}
+
+	bodyBytes, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(bodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if r.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+r.APIKey)
+	} else {
+		creds, ok := awsCredentialsFromEnv()
+		if !ok {
+			return nil, fmt.Errorf("no AWS credentials found for Bedrock")
+		}
+		signAWSRequest(req, bodyBytes, creds, bedrockRegion(), "bedrock", time.Now())
+	}
+
+	resp, err := r.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("calling Bedrock API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, newAPIError("Bedrock", resp)
+	}
+
+	var bedrockResp bedrockResponse
+	if err := json.NewDecoder(resp.Body).Decode(&bedrockResp); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	usage := Usage{
+		InputTokens:  bedrockResp.Usage.InputTokens,
+		OutputTokens: bedrockResp.Usage.OutputTokens,
+	}
+
+	var result string
+	for _, block := range bedrockResp.Output.Message.Content {
+		if block.ToolUse != nil && r.Schema != nil {
+			return &Response{Text: string(block.ToolUse.Input), Usage: usage}, nil
+		}
+		result += block.Text
+	}
+	if result == "" {
+		return nil, fmt.Errorf("empty response from Bedrock")
+	}
+
+	return &Response{Text: result, Usage: usage}, nil
+}
+
+func bedrockRegion() string {
+	for _, env := range []string{"AWS_REGION", "AWS_DEFAULT_REGION"} {
+		if v := os.Getenv(env); v != "" {
+			return v
+		}
+	}
+	return BedrockDefaultRegion
+}
+
+type awsCredentials struct {
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+}
+
+func awsCredentialsFromEnv() (awsCredentials, bool) {
+	creds := awsCredentials{
+		accessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		sessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	return creds, creds.accessKeyID != "" && creds.secretAccessKey != ""
+}
+
+// signAWSRequest adds AWS Signature Version 4 headers to req.
+func signAWSRequest(req *http.Request, body []byte, creds awsCredentials, region, service string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	dateStamp := now.UTC().Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if creds.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.sessionToken)
+	}
+
+	signedHeaders := []string{"content-type", "host", "x-amz-date"}
+	if creds.sessionToken != "" {
+		signedHeaders = append(signedHeaders, "x-amz-security-token")
+	}
+	var canonicalHeaders strings.Builder
+	for _, h := range signedHeaders {
+		value := req.Header.Get(h)
+		if h == "host" {
+			value = req.URL.Host
+		}
+		canonicalHeaders.WriteString(h + ":" + strings.TrimSpace(value) + "\n")
+	}
+
+	// Services other than S3 expect each path segment to be encoded again
+	segments := strings.Split(req.URL.EscapedPath(), "/")
+	for i, seg := range segments {
+		segments[i] = awsURIEncode(seg)
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		strings.Join(segments, "/"),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		strings.Join(signedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{dateStamp, region, service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+creds.secretAccessKey), dateStamp)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.accessKeyID, scope, strings.Join(signedHeaders, ";"), signature))
+}
+
+// awsURIEncode percent-encodes everything except RFC 3986 unreserved characters.
+func awsURIEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// Bedrock Converse API types
+type bedrockRequest struct {
+	Messages        []bedrockMessage       `json:"messages"`
+	InferenceConfig bedrockInferenceConfig `json:"inferenceConfig"`
+	ToolConfig      *bedrockToolConfig     `json:"toolConfig,omitempty"`
+}
+
+type bedrockToolConfig struct {
+	Tools      []bedrockTool          `json:"tools"`
+	ToolChoice map[string]interface{} `json:"toolChoice,omitempty"`
+}
+
+type bedrockTool struct {
+	ToolSpec bedrockToolSpec `json:"toolSpec"`
+}
+
+type bedrockToolSpec struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description,omitempty"`
+	InputSchema bedrockInputSchema `json:"inputSchema"`
+}
+
+type bedrockInputSchema struct {
+	JSON map[string]interface{} `json:"json"`
+}
+
+type bedrockMessage struct {
+	Role    string                `json:"role"`
+	Content []bedrockContentBlock `json:"content"`
+}
+
+type bedrockContentBlock struct {
+	Text    string          `json:"text,omitempty"`
+	ToolUse *bedrockToolUse `json:"toolUse,omitempty"`
+}
+
+type bedrockToolUse struct {
+	Name  string          `json:"name"`
+	Input json.RawMessage `json:"input"`
+}
+
+type bedrockInferenceConfig struct {
+	MaxTokens   int     `json:"maxTokens"`
+	Temperature float64 `json:"temperature"`
+}
+
+type bedrockResponse struct {
+	Output struct {
+		Message bedrockMessage `json:"message"`
+	} `json:"output"`
+	Usage struct {
+		InputTokens  int `json:"inputTokens"`
+		OutputTokens int `json:"outputTokens"`
+	} `json:"usage"`
+}
@@ -0,0 +1,60 @@
+package llm
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"sort"
+)
+
+// Prompt template versions are part of cache keys. Bump a version whenever
+// its prompt or the way it is built changes, so cached responses to the old
+// prompt are no longer used.
+const (
+	CompliancePromptVersion       = "compliance-3"
+	PolicyExtractionPromptVersion = "policy-extraction-1"
+)
+
+// Cache stores LLM responses so that calls with unchanged inputs can be
+// skipped. Implementations decide how long entries stay valid.
+type Cache interface {
+	Get(key string) (string, bool)
+	Put(key, provider, model, response string)
+}
+
+// CacheKey identifies a response by the client's provider and model, the
+// prompt template version, the hash of the rules in the prompt and the hash
+// of the content being analyzed.
+func (c *Client) CacheKey(promptVersion, ruleSetHash, contentHash string) string {
+	return HashStrings(c.provider.Name(), c.model, promptVersion, ruleSetHash, contentHash)
+}
+
+// HashStrings returns the hex SHA-256 of the strings, length-prefixed so
+// that different splits of the same text hash differently.
+func HashStrings(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		var n [8]byte
+		binary.LittleEndian.PutUint64(n[:], uint64(len(p)))
+		h.Write(n[:])
+		h.Write([]byte(p))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// HashFiles returns a hash of file paths and contents that does not depend
+// on map order.
+func HashFiles(files map[string]string) string {
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	parts := make([]string, 0, 2*len(paths))
+	for _, path := range paths {
+		sum := sha256.Sum256([]byte(files[path]))
+		parts = append(parts, path, hex.EncodeToString(sum[:]))
+	}
+	return HashStrings(parts...)
+}
@@ -4,129 +4,236 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 )
 
 const (
-	ProviderGemini    = "gemini"
-	ProviderOpenAI    = "openai"
-	ProviderAnthropic = "anthropic"
-)
+	ProviderGemini      = "gemini"
+	ProviderOpenAI      = "openai"
+	ProviderAnthropic   = "anthropic"
+	ProviderLocal       = "local"
+	ProviderAzureOpenAI = "azure-openai"
+	ProviderBedrock     = "bedrock"
 
-var ValidProviders = []string{ProviderGemini, ProviderOpenAI, ProviderAnthropic}
+	// ProviderOpenAICompatible is accepted as an alias for ProviderLocal.
+	ProviderOpenAICompatible = "openai-compatible"
+)
 
 type Client struct {
-	provider   string
+	provider   Provider
 	apiKey     string
 	model      string
+	baseURL    string
+	maxRetries int
+	limiter    *rateLimiter
 	httpClient *http.Client
+	cache      Cache
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+// Options configures a Client. Zero values select the defaults, except
+// MaxRetries where zero disables retries.
+type Options struct {
+	Provider string
+	APIKey   string
+	Model    string
+	// BaseURL overrides the provider's default endpoint
+	BaseURL string
+
+	MaxRetries int
+	// Timeout bounds a single HTTP request, not the retries around it
+	Timeout time.Duration
+	// RequestsPerMinute caps requests to the provider across all clients
+	RequestsPerMinute int
+	// Cache, if set, is used by GenerateJSONCached
+	Cache Cache
+}
+
+// New creates a client from opts for a registered provider.
+func New(opts Options) *Client {
+	return NewWithProvider(ProviderFor(opts.Provider), opts)
 }
 
-func NewClient(provider, apiKey, model string) *Client {
-	if provider == "" {
-		provider = ProviderGemini
+// NewWithProvider creates a client for a provider that need not be
+// registered, such as llmtest.FakeProvider in tests. opts.Provider is ignored.
+func NewWithProvider(p Provider, opts Options) *Client {
+	baseURL := opts.BaseURL
+	if baseURL == "" {
+		baseURL = p.DefaultBaseURL()
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	maxRetries := opts.MaxRetries
+	if maxRetries < 0 {
+		maxRetries = 0
 	}
-	model = ValidateModel(provider, model)
 	return &Client{
-		provider:   provider,
-		apiKey:     apiKey,
-		model:      model,
-		httpClient: &http.Client{Timeout: 180 * time.Second},
+		provider:   p,
+		apiKey:     opts.APIKey,
+		model:      p.ValidateModel(opts.Model),
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		maxRetries: maxRetries,
+		limiter:    limiterFor(p.Name(), opts.RequestsPerMinute),
+		httpClient: &http.Client{Timeout: timeout},
+		cache:      opts.Cache,
 	}
 }
 
 // GenerateContent sends a prompt to the configured LLM provider and returns the text response.
+// Rate limits, overload and transient network errors are retried with backoff.
 func (c *Client) GenerateContent(ctx context.Context, prompt string) (string, error) {
-	switch c.provider {
-	case ProviderOpenAI:
-		return c.generateOpenAI(ctx, prompt)
-	case ProviderAnthropic:
-		return c.generateAnthropic(ctx, prompt)
-	default:
-		return c.generateGemini(ctx, prompt)
+	return c.generate(ctx, prompt, nil)
+}
+
+// GenerateJSON sends a prompt that must produce JSON matching schema and
+// unmarshals the result into target. The provider's native structured output
+// is used, and invalid output gets one repair re-prompt. If the output still
+// cannot be parsed the returned error wraps ErrInvalidJSON.
+func (c *Client) GenerateJSON(ctx context.Context, prompt string, schema *Schema, target interface{}) error {
+	_, err := c.generateJSON(ctx, prompt, schema, target)
+	return err
+}
+
+// GenerateJSONCached is GenerateJSON with the parsed response cached under
+// key, which should come from CacheKey. Without a cache it is GenerateJSON.
+func (c *Client) GenerateJSONCached(ctx context.Context, key, prompt string, schema *Schema, target interface{}) error {
+	if c.cache == nil {
+		return c.GenerateJSON(ctx, prompt, schema, target)
+	}
+	if text, ok := c.cache.Get(key); ok && ParseJSONResponse(text, target) == nil {
+		return nil
+	}
+
+	text, err := c.generateJSON(ctx, prompt, schema, target)
+	if err != nil {
+		return err
+	}
+	c.cache.Put(key, c.provider.Name(), c.model, text)
+	return nil
+}
+
+// generateJSON implements GenerateJSON and returns the text that parsed.
+func (c *Client) generateJSON(ctx context.Context, prompt string, schema *Schema, target interface{}) (string, error) {
+	text, err := c.generate(ctx, prompt, schema)
+	if err != nil {
+		return "", err
+	}
+	parseErr := ParseJSONResponse(text, target)
+	if parseErr == nil {
+		return text, nil
+	}
+
+	text, err = c.generate(ctx, BuildJSONRepairPrompt(schema, text, parseErr), schema)
+	if err != nil {
+		return "", err
+	}
+	if err := ParseJSONResponse(text, target); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+	}
+	return text, nil
+}
+
+func (c *Client) generate(ctx context.Context, prompt string, schema *Schema) (string, error) {
+	req := &Request{
+		Prompt:     prompt,
+		Schema:     schema,
+		Model:      c.model,
+		APIKey:     c.apiKey,
+		BaseURL:    c.baseURL,
+		HTTPClient: c.httpClient,
 	}
+
+	for attempt := 0; ; attempt++ {
+		if c.limiter != nil {
+			if err := c.limiter.Wait(ctx); err != nil {
+				return "", err
+			}
+		}
+
+		resp, err := c.provider.Generate(ctx, req)
+		if err == nil {
+			c.record(resp.Usage)
+			return resp.Text, nil
+		}
+		if attempt >= c.maxRetries || ctx.Err() != nil || !IsRetryable(err) {
+			if attempt > 0 {
+				return "", fmt.Errorf("%w (after %d attempts)", err, attempt+1)
+			}
+			return "", err
+		}
+
+		delay, ok := retryDelay(err, attempt)
+		if !ok {
+			return "", err
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return "", err
+		}
+	}
+}
+
+func (c *Client) record(u Usage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls = append(c.calls, Call{Provider: c.provider.Name(), Model: c.model, Usage: u})
+}
+
+// TakeCalls returns the usage of the calls made since the last TakeCalls and
+// forgets them, so the caller can attribute them to what it is working on.
+func (c *Client) TakeCalls() []Call {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	calls := c.calls
+	c.calls = nil
+	return calls
 }
 
 // ValidateModel checks that the model is valid for the given provider, falling back to the default.
 func ValidateModel(provider, model string) string {
-	switch provider {
-	case ProviderOpenAI:
-		return validateOpenAIModel(model)
-	case ProviderAnthropic:
-		return validateAnthropicModel(model)
-	default:
-		return validateGeminiModel(model)
-	}
+	return ProviderFor(provider).ValidateModel(model)
+}
+
+// NormalizeProvider resolves provider aliases and applies the default provider.
+func NormalizeProvider(provider string) string {
+	return ProviderFor(provider).Name()
 }
 
 // IsValidProvider returns true if the given provider name is supported.
 func IsValidProvider(provider string) bool {
-	for _, p := range ValidProviders {
-		if p == provider {
-			return true
-		}
-	}
-	return false
+	_, ok := LookupProvider(provider)
+	return ok
 }
 
 // DefaultModelForProvider returns the default model for the given provider.
 func DefaultModelForProvider(provider string) string {
-	switch provider {
-	case ProviderOpenAI:
-		return OpenAIDefaultModel
-	case ProviderAnthropic:
-		return AnthropicDefaultModel
-	default:
-		return GeminiDefaultModel
-	}
+	return ProviderFor(provider).DefaultModel()
+}
+
+// DefaultBaseURL returns the API endpoint used when no base URL is configured.
+func DefaultBaseURL(provider string) string {
+	return ProviderFor(provider).DefaultBaseURL()
 }
 
 // ProviderLabel returns a human-readable label for a provider.
 func ProviderLabel(provider string) string {
-	switch provider {
-	case ProviderOpenAI:
-		return "OpenAI"
-	case ProviderAnthropic:
-		return "Anthropic"
-	default:
-		return "Google Gemini"
-	}
+	return ProviderFor(provider).Label()
 }
 
 // ValidModelsForProvider returns the list of valid models for a given provider.
+// Providers that accept any model name return nil.
 func ValidModelsForProvider(provider string) []string {
-	switch provider {
-	case ProviderOpenAI:
-		var models []string
-		for m := range openAIValidModels {
-			models = append(models, m)
-		}
-		return models
-	case ProviderAnthropic:
-		var models []string
-		for m := range anthropicValidModels {
-			models = append(models, m)
-		}
-		return models
-	default:
-		var models []string
-		for m := range geminiValidModels {
-			models = append(models, m)
-		}
-		return models
-	}
+	return ProviderFor(provider).Models()
 }
 
 // APIKeyEnvVar returns the environment variable name for the provider's API key.
 func APIKeyEnvVar(provider string) string {
-	switch provider {
-	case ProviderOpenAI:
-		return "OPENAI_API_KEY"
-	case ProviderAnthropic:
-		return "ANTHROPIC_API_KEY"
-	default:
-		return "GEMINI_API_KEY"
-	}
+	return ProviderFor(provider).APIKeyEnvVar()
 }
 
 // HasAPIKey checks if the client has a non-empty API key.
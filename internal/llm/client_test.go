@@ -0,0 +1,147 @@
+package llm_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nerifect/nerifect-cli/internal/llm"
+	"github.com/nerifect/nerifect-cli/internal/llm/llmtest"
+)
+
+// overloaded is a retryable error whose Retry-After keeps the test fast.
+func overloaded() error {
+	return &llm.APIError{Provider: "fake", StatusCode: http.StatusServiceUnavailable, RetryAfter: time.Millisecond}
+}
+
+func TestGenerateContentRetries(t *testing.T) {
+	fake := &llmtest.FakeProvider{Responses: []string{"ok"}, Errors: []error{overloaded(), overloaded()}}
+	client := llm.NewWithProvider(fake, llm.Options{MaxRetries: 2})
+
+	text, err := client.GenerateContent(context.Background(), "prompt")
+	if err != nil {
+		t.Fatalf("GenerateContent: %v", err)
+	}
+	if text != "ok" {
+		t.Errorf("GenerateContent = %q, want %q", text, "ok")
+	}
+	if n := len(fake.Prompts()); n != 3 {
+		t.Errorf("provider called %d times, want 3", n)
+	}
+}
+
+func TestGenerateContentGivesUpAfterMaxRetries(t *testing.T) {
+	fake := &llmtest.FakeProvider{Responses: []string{"ok"}, Errors: []error{overloaded(), overloaded()}}
+	client := llm.NewWithProvider(fake, llm.Options{MaxRetries: 1})
+
+	_, err := client.GenerateContent(context.Background(), "prompt")
+	var apiErr *llm.APIError
+	if !errors.As(err, &apiErr) || !strings.Contains(err.Error(), "after 2 attempts") {
+		t.Fatalf("GenerateContent error = %v, want the API error after 2 attempts", err)
+	}
+	if n := len(fake.Prompts()); n != 2 {
+		t.Errorf("provider called %d times, want 2", n)
+	}
+}
+
+func TestGenerateContentDoesNotRetryBadRequest(t *testing.T) {
+	badRequest := &llm.APIError{Provider: "fake", StatusCode: http.StatusBadRequest}
+	fake := &llmtest.FakeProvider{Responses: []string{"ok"}, Errors: []error{badRequest}}
+	client := llm.NewWithProvider(fake, llm.Options{MaxRetries: 3})
+
+	if _, err := client.GenerateContent(context.Background(), "prompt"); !errors.Is(err, badRequest) {
+		t.Fatalf("GenerateContent error = %v, want %v", err, badRequest)
+	}
+	if n := len(fake.Prompts()); n != 1 {
+		t.Errorf("provider called %d times, want 1", n)
+	}
+}
+
+type fixResult struct {
+	FixDescription string  `json:"fix_description"`
+	Confidence     float64 `json:"confidence"`
+}
+
+func TestGenerateJSON(t *testing.T) {
+	client, fake := llmtest.NewFakeClient("```json\n{\"fix_description\": \"remove key\", \"confidence\": 0.9}\n```")
+
+	var result fixResult
+	if err := client.GenerateJSON(context.Background(), "prompt", llm.FixSchema, &result); err != nil {
+		t.Fatalf("GenerateJSON: %v", err)
+	}
+	if result.FixDescription != "remove key" || result.Confidence != 0.9 {
+		t.Errorf("GenerateJSON result = %+v", result)
+	}
+	if n := len(fake.Prompts()); n != 1 {
+		t.Errorf("provider called %d times, want 1", n)
+	}
+}
+
+func TestGenerateJSONRepairsInvalidOutput(t *testing.T) {
+	client, fake := llmtest.NewFakeClient(
+		`{"fix_description": "remove key", "confidence": `,
+		`{"fix_description": "remove key", "confidence": 0.5}`,
+	)
+
+	var result fixResult
+	if err := client.GenerateJSON(context.Background(), "prompt", llm.FixSchema, &result); err != nil {
+		t.Fatalf("GenerateJSON: %v", err)
+	}
+	if result.Confidence != 0.5 {
+		t.Errorf("GenerateJSON result = %+v, want the repaired response", result)
+	}
+	prompts := fake.Prompts()
+	if len(prompts) != 2 {
+		t.Fatalf("provider called %d times, want 2", len(prompts))
+	}
+	if !strings.Contains(prompts[1], `"confidence": `) || !strings.Contains(prompts[1], "fix_diff") {
+		t.Errorf("repair prompt does not include the invalid output and the schema:\n%s", prompts[1])
+	}
+}
+
+func TestGenerateJSONFailsAfterOneRepair(t *testing.T) {
+	client, fake := llmtest.NewFakeClient("not json")
+
+	var result fixResult
+	err := client.GenerateJSON(context.Background(), "prompt", llm.FixSchema, &result)
+	if !errors.Is(err, llm.ErrInvalidJSON) {
+		t.Fatalf("GenerateJSON error = %v, want ErrInvalidJSON", err)
+	}
+	if n := len(fake.Prompts()); n != 2 {
+		t.Errorf("provider called %d times, want 2", n)
+	}
+}
+
+// mapCache is an in-memory llm.Cache.
+type mapCache map[string]string
+
+func (c mapCache) Get(key string) (string, bool) {
+	text, ok := c[key]
+	return text, ok
+}
+
+func (c mapCache) Put(key, provider, model, response string) {
+	c[key] = response
+}
+
+func TestGenerateJSONCached(t *testing.T) {
+	fake := &llmtest.FakeProvider{Responses: []string{`{"fix_description": "remove key", "confidence": 0.9}`}}
+	client := llm.NewWithProvider(fake, llm.Options{Cache: mapCache{}})
+	key := client.CacheKey("test-1", "rules", "content")
+
+	for i := 0; i < 2; i++ {
+		var result fixResult
+		if err := client.GenerateJSONCached(context.Background(), key, "prompt", llm.FixSchema, &result); err != nil {
+			t.Fatalf("GenerateJSONCached: %v", err)
+		}
+		if result.Confidence != 0.9 {
+			t.Errorf("GenerateJSONCached result = %+v", result)
+		}
+	}
+	if n := len(fake.Prompts()); n != 1 {
+		t.Errorf("provider called %d times, want 1", n)
+	}
+}
@@ -5,8 +5,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
+	"strings"
 )
 
 const (
@@ -29,69 +29,110 @@ var geminiDeprecatedModels = map[string]string{
 	"gemini-ultra":     "gemini-2.5-pro",
 }
 
-func validateGeminiModel(model string) string {
-	if model == "" {
-		return GeminiDefaultModel
-	}
-	if geminiValidModels[model] {
-		return model
-	}
-	if replacement, ok := geminiDeprecatedModels[model]; ok {
-		return replacement
-	}
-	return GeminiDefaultModel
+func init() {
+	Register(geminiProvider{modelCatalog{
+		defaultModel: GeminiDefaultModel,
+		valid:        geminiValidModels,
+		deprecated:   geminiDeprecatedModels,
+	}})
 }
 
-func (c *Client) generateGemini(ctx context.Context, prompt string) (string, error) {
-	url := fmt.Sprintf("%s/models/%s:generateContent?key=%s", GeminiBaseURL, c.model, c.apiKey)
+type geminiProvider struct {
+	modelCatalog
+}
+
+func (geminiProvider) Name() string            { return ProviderGemini }
+func (geminiProvider) Label() string           { return "Google Gemini" }
+func (geminiProvider) DefaultBaseURL() string  { return GeminiBaseURL }
+func (geminiProvider) APIKeyEnvVar() string    { return "GEMINI_API_KEY" }
+func (geminiProvider) APIKeyConfigKey() string { return "gemini_api_key" }
+
+func (p geminiProvider) CheckCredentials(apiKey string) error {
+	return requireAPIKey(p, apiKey)
+}
+
+func (geminiProvider) Generate(ctx context.Context, r *Request) (*Response, error) {
+	url := fmt.Sprintf("%s/models/%s:generateContent?key=%s", r.BaseURL, r.Model, r.APIKey)
 
 	reqBody := geminiRequest{
 		Contents: []geminiContent{
-			{Parts: []geminiPart{{Text: prompt}}},
+			{Parts: []geminiPart{{Text: r.Prompt}}},
 		},
 		GenerationConfig: &geminiGenerationConfig{
 			Temperature:     0.2,
 			MaxOutputTokens: 8192,
 		},
 	}
+	if r.Schema != nil {
+		reqBody.GenerationConfig.ResponseMimeType = "application/json"
+		reqBody.GenerationConfig.ResponseSchema = geminiSchema(r.Schema.Definition)
+	}
 
 	bodyBytes, err := json.Marshal(reqBody)
 	if err != nil {
-		return "", fmt.Errorf("marshaling request: %w", err)
+		return nil, fmt.Errorf("marshaling request: %w", err)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(bodyBytes))
 	if err != nil {
-		return "", fmt.Errorf("creating request: %w", err)
+		return nil, fmt.Errorf("creating request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := r.HTTPClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("calling Gemini API: %w", err)
+		return nil, fmt.Errorf("calling Gemini API: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("Gemini API error %d: %s", resp.StatusCode, string(body))
+		return nil, newAPIError("Gemini", resp)
 	}
 
 	var geminiResp geminiResponse
 	if err := json.NewDecoder(resp.Body).Decode(&geminiResp); err != nil {
-		return "", fmt.Errorf("decoding response: %w", err)
+		return nil, fmt.Errorf("decoding response: %w", err)
 	}
 
 	if len(geminiResp.Candidates) == 0 || len(geminiResp.Candidates[0].Content.Parts) == 0 {
-		return "", fmt.Errorf("empty response from Gemini")
+		return nil, fmt.Errorf("empty response from Gemini")
 	}
 
-	return geminiResp.Candidates[0].Content.Parts[0].Text, nil
+	return &Response{
+		Text: geminiResp.Candidates[0].Content.Parts[0].Text,
+		Usage: Usage{
+			InputTokens:  geminiResp.UsageMetadata.PromptTokenCount,
+			OutputTokens: geminiResp.UsageMetadata.CandidatesTokenCount,
+		},
+	}, nil
+}
+
+// geminiSchema converts a JSON Schema to Gemini's OpenAPI-style schema,
+// which spells types in upper case.
+func geminiSchema(schema map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(schema))
+	for k, v := range schema {
+		switch k {
+		case "type":
+			out[k] = strings.ToUpper(v.(string))
+		case "items":
+			out[k] = geminiSchema(v.(map[string]interface{}))
+		case "properties":
+			props := make(map[string]interface{})
+			for name, prop := range v.(map[string]interface{}) {
+				props[name] = geminiSchema(prop.(map[string]interface{}))
+			}
+			out[k] = props
+		default:
+			out[k] = v
+		}
+	}
+	return out
 }
 
 // Gemini API types
 type geminiRequest struct {
-	Contents         []geminiContent          `json:"contents"`
+	Contents         []geminiContent         `json:"contents"`
 	GenerationConfig *geminiGenerationConfig `json:"generationConfig,omitempty"`
 }
 
@@ -104,12 +145,18 @@ type geminiPart struct {
 }
 
 type geminiGenerationConfig struct {
-	Temperature     float64 `json:"temperature"`
-	MaxOutputTokens int     `json:"maxOutputTokens"`
+	Temperature      float64                `json:"temperature"`
+	MaxOutputTokens  int                    `json:"maxOutputTokens"`
+	ResponseMimeType string                 `json:"responseMimeType,omitempty"`
+	ResponseSchema   map[string]interface{} `json:"responseSchema,omitempty"`
 }
 
 type geminiResponse struct {
-	Candidates []geminiCandidate `json:"candidates"`
+	Candidates    []geminiCandidate `json:"candidates"`
+	UsageMetadata struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+	} `json:"usageMetadata"`
 }
 
 type geminiCandidate struct {
@@ -0,0 +1,68 @@
+// Package llmtest provides an in-memory LLM provider for tests.
+package llmtest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/nerifect/nerifect-cli/internal/llm"
+)
+
+// FakeProvider is an in-memory llm.Provider. It is not registered; wrap it
+// with llm.NewWithProvider or use NewFakeClient. Responses are returned in
+// order and the last one is repeated once the queue is exhausted.
+type FakeProvider struct {
+	Responses []string
+	// Errors are returned from the calls in order instead of a response. A
+	// nil entry, or any call after the last entry, gets the next response.
+	Errors []error
+
+	mu      sync.Mutex
+	prompts []string
+	served  int
+}
+
+// NewFakeClient returns a client backed by a new FakeProvider with the given
+// responses. Retries are disabled; use llm.NewWithProvider to set options.
+func NewFakeClient(responses ...string) (*llm.Client, *FakeProvider) {
+	fake := &FakeProvider{Responses: responses}
+	return llm.NewWithProvider(fake, llm.Options{}), fake
+}
+
+func (f *FakeProvider) Name() string                         { return "fake" }
+func (f *FakeProvider) Label() string                        { return "Fake" }
+func (f *FakeProvider) DefaultModel() string                 { return "fake-model" }
+func (f *FakeProvider) ValidateModel(model string) string    { return f.DefaultModel() }
+func (f *FakeProvider) Models() []string                     { return []string{f.DefaultModel()} }
+func (f *FakeProvider) DefaultBaseURL() string               { return "" }
+func (f *FakeProvider) APIKeyEnvVar() string                 { return "" }
+func (f *FakeProvider) APIKeyConfigKey() string              { return "" }
+func (f *FakeProvider) CheckCredentials(apiKey string) error { return nil }
+
+func (f *FakeProvider) Generate(ctx context.Context, r *llm.Request) (*llm.Response, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	call := len(f.prompts)
+	f.prompts = append(f.prompts, r.Prompt)
+	if call < len(f.Errors) && f.Errors[call] != nil {
+		return nil, f.Errors[call]
+	}
+	if len(f.Responses) == 0 {
+		return nil, fmt.Errorf("fake provider has no responses")
+	}
+	i := f.served
+	if i >= len(f.Responses) {
+		i = len(f.Responses) - 1
+	}
+	f.served++
+	return &llm.Response{Text: f.Responses[i]}, nil
+}
+
+// Prompts returns the prompts received so far.
+func (f *FakeProvider) Prompts() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.prompts...)
+}
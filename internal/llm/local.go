@@ -0,0 +1,40 @@
+package llm
+
+import (
+	"context"
+)
+
+const (
+	// LocalDefaultBaseURL points at Ollama's OpenAI-compatible endpoint.
+	LocalDefaultBaseURL = "http://localhost:11434/v1"
+	LocalDefaultModel   = "llama3.1"
+)
+
+func init() {
+	// Local servers (Ollama, vLLM, llama.cpp) serve whatever model the
+	// operator has loaded, so any model name is accepted.
+	Register(localProvider{modelCatalog{
+		defaultModel: LocalDefaultModel,
+		freeForm:     true,
+	}}, ProviderOpenAICompatible)
+}
+
+type localProvider struct {
+	modelCatalog
+}
+
+func (localProvider) Name() string            { return ProviderLocal }
+func (localProvider) Label() string           { return "OpenAI-compatible (local)" }
+func (localProvider) DefaultBaseURL() string  { return LocalDefaultBaseURL }
+func (localProvider) APIKeyEnvVar() string    { return "NERIFECT_LLM_API_KEY" }
+func (localProvider) APIKeyConfigKey() string { return "local_api_key" }
+
+// CheckCredentials always succeeds: local servers usually run without
+// authentication, and the key is sent only when one is configured.
+func (localProvider) CheckCredentials(apiKey string) error {
+	return nil
+}
+
+func (localProvider) Generate(ctx context.Context, r *Request) (*Response, error) {
+	return chatCompletion(ctx, r, r.BaseURL+"/chat/completions", "local LLM", false, bearerAuth(r.APIKey))
+}
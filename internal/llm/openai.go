@@ -5,8 +5,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
+	"sort"
 )
 
 const (
@@ -15,79 +15,120 @@ const (
 )
 
 var openAIValidModels = map[string]bool{
-	"gpt-4o":        true,
-	"gpt-4o-mini":   true,
-	"gpt-4-turbo":   true,
-	"gpt-4.1":       true,
-	"gpt-4.1-mini":  true,
-	"gpt-4.1-nano":  true,
-	"o3-mini":       true,
+	"gpt-4o":       true,
+	"gpt-4o-mini":  true,
+	"gpt-4-turbo":  true,
+	"gpt-4.1":      true,
+	"gpt-4.1-mini": true,
+	"gpt-4.1-nano": true,
+	"o3-mini":      true,
 }
 
 var openAIDeprecatedModels = map[string]string{
-	"gpt-4":              "gpt-4o",
-	"gpt-3.5-turbo":      "gpt-4o-mini",
+	"gpt-4":               "gpt-4o",
+	"gpt-3.5-turbo":       "gpt-4o-mini",
 	"gpt-4-turbo-preview": "gpt-4-turbo",
 }
 
-func validateOpenAIModel(model string) string {
-	if model == "" {
-		return OpenAIDefaultModel
-	}
-	if openAIValidModels[model] {
-		return model
-	}
-	if replacement, ok := openAIDeprecatedModels[model]; ok {
-		return replacement
-	}
-	return OpenAIDefaultModel
+func init() {
+	Register(openAIProvider{modelCatalog{
+		defaultModel: OpenAIDefaultModel,
+		valid:        openAIValidModels,
+		deprecated:   openAIDeprecatedModels,
+	}})
+}
+
+type openAIProvider struct {
+	modelCatalog
+}
+
+func (openAIProvider) Name() string            { return ProviderOpenAI }
+func (openAIProvider) Label() string           { return "OpenAI" }
+func (openAIProvider) DefaultBaseURL() string  { return OpenAIBaseURL }
+func (openAIProvider) APIKeyEnvVar() string    { return "OPENAI_API_KEY" }
+func (openAIProvider) APIKeyConfigKey() string { return "openai_api_key" }
+
+func (p openAIProvider) CheckCredentials(apiKey string) error {
+	return requireAPIKey(p, apiKey)
 }
 
-func (c *Client) generateOpenAI(ctx context.Context, prompt string) (string, error) {
-	url := fmt.Sprintf("%s/chat/completions", OpenAIBaseURL)
+func (openAIProvider) Generate(ctx context.Context, r *Request) (*Response, error) {
+	return chatCompletion(ctx, r, r.BaseURL+"/chat/completions", "OpenAI", true, bearerAuth(r.APIKey))
+}
+
+// bearerAuth sets an "Authorization: Bearer" header, or nothing if apiKey is empty.
+func bearerAuth(apiKey string) func(*http.Request) {
+	return func(req *http.Request) {
+		if apiKey != "" {
+			req.Header.Set("Authorization", "Bearer "+apiKey)
+		}
+	}
+}
 
+// chatCompletion calls an OpenAI-style chat completions endpoint. label
+// names the backend in error messages and auth adds credentials to the request.
+// With strict, the backend is asked to enforce the response schema, which
+// OpenAI and Azure support but many local servers do not.
+func chatCompletion(ctx context.Context, r *Request, url, label string, strict bool, auth func(*http.Request)) (*Response, error) {
 	reqBody := openAIRequest{
-		Model: c.model,
+		Model: r.Model,
 		Messages: []openAIMessage{
-			{Role: "user", Content: prompt},
+			{Role: "user", Content: r.Prompt},
 		},
 		Temperature: floatPtr(0.2),
 		MaxTokens:   8192,
 	}
+	if r.Schema != nil {
+		format := &openAIJSONSchema{
+			Name:        r.Schema.Name,
+			Description: r.Schema.Description,
+			Schema:      r.Schema.Definition,
+		}
+		if strict {
+			format.Strict = true
+			format.Schema = strictSchema(r.Schema.Definition)
+		}
+		reqBody.ResponseFormat = &openAIResponseFormat{Type: "json_schema", JSONSchema: format}
+	}
 
 	bodyBytes, err := json.Marshal(reqBody)
 	if err != nil {
-		return "", fmt.Errorf("marshaling request: %w", err)
+		return nil, fmt.Errorf("marshaling request: %w", err)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(bodyBytes))
 	if err != nil {
-		return "", fmt.Errorf("creating request: %w", err)
+		return nil, fmt.Errorf("creating request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	auth(req)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := r.HTTPClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("calling OpenAI API: %w", err)
+		return nil, fmt.Errorf("calling %s API: %w", label, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("OpenAI API error %d: %s", resp.StatusCode, string(body))
+		return nil, newAPIError(label, resp)
 	}
 
 	var openAIResp openAIResponse
 	if err := json.NewDecoder(resp.Body).Decode(&openAIResp); err != nil {
-		return "", fmt.Errorf("decoding response: %w", err)
+		return nil, fmt.Errorf("decoding response: %w", err)
 	}
 
 	if len(openAIResp.Choices) == 0 {
-		return "", fmt.Errorf("empty response from OpenAI")
+		return nil, fmt.Errorf("empty response from %s", label)
 	}
 
-	return openAIResp.Choices[0].Message.Content, nil
+	return &Response{
+		Text: openAIResp.Choices[0].Message.Content,
+		Usage: Usage{
+			InputTokens:  openAIResp.Usage.PromptTokens,
+			OutputTokens: openAIResp.Usage.CompletionTokens,
+		},
+	}, nil
 }
 
 func floatPtr(f float64) *float64 {
@@ -100,6 +141,76 @@ type openAIRequest struct {
 	Messages    []openAIMessage `json:"messages"`
 	Temperature *float64        `json:"temperature,omitempty"`
 	MaxTokens   int             `json:"max_tokens,omitempty"`
+
+	ResponseFormat *openAIResponseFormat `json:"response_format,omitempty"`
+}
+
+type openAIResponseFormat struct {
+	Type       string            `json:"type"`
+	JSONSchema *openAIJSONSchema `json:"json_schema,omitempty"`
+}
+
+type openAIJSONSchema struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Schema      map[string]interface{} `json:"schema"`
+	Strict      bool                   `json:"strict,omitempty"`
+}
+
+// strictSchema adapts a schema to OpenAI's strict mode, which requires every
+// object to list all of its properties as required and to forbid others.
+// Properties that were optional become nullable instead.
+func strictSchema(schema map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(schema)+1)
+	for k, v := range schema {
+		out[k] = v
+	}
+	if items, ok := schema["items"].(map[string]interface{}); ok {
+		out["items"] = strictSchema(items)
+	}
+	properties, ok := schema["properties"].(map[string]interface{})
+	if !ok {
+		return out
+	}
+
+	required := make(map[string]bool)
+	if names, ok := schema["required"].([]string); ok {
+		for _, name := range names {
+			required[name] = true
+		}
+	}
+	strictProps := make(map[string]interface{}, len(properties))
+	names := make([]string, 0, len(properties))
+	for name, p := range properties {
+		prop := strictSchema(p.(map[string]interface{}))
+		if !required[name] {
+			prop = nullable(prop)
+		}
+		strictProps[name] = prop
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	out["properties"] = strictProps
+	out["required"] = names
+	out["additionalProperties"] = false
+	return out
+}
+
+// nullable allows null in place of the value a schema describes.
+func nullable(schema map[string]interface{}) map[string]interface{} {
+	t, ok := schema["type"].(string)
+	if !ok {
+		return schema
+	}
+	schema["type"] = []string{t, "null"}
+	if values, ok := schema["enum"].([]string); ok {
+		enum := make([]interface{}, 0, len(values)+1)
+		for _, v := range values {
+			enum = append(enum, v)
+		}
+		schema["enum"] = append(enum, nil)
+	}
+	return schema
 }
 
 type openAIMessage struct {
@@ -109,6 +220,10 @@ type openAIMessage struct {
 
 type openAIResponse struct {
 	Choices []openAIChoice `json:"choices"`
+	Usage   struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
 }
 
 type openAIChoice struct {
@@ -3,6 +3,7 @@ package llm
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 )
 
 // ComplianceEvaluationPrompt is ported from backend/ai/compliance_evaluator.py
@@ -12,6 +13,8 @@ const ComplianceEvaluationPrompt = `You are a compliance expert evaluating sourc
 %s
 
 ## Source Files to Analyze
+Each line of a file's content is prefixed with its line number and a "|"
+separator, which are NOT part of the file.
 %s
 
 ## Instructions
@@ -19,6 +22,7 @@ const ComplianceEvaluationPrompt = `You are a compliance expert evaluating sourc
 2. Identify SPECIFIC violations in the code
 3. Reference the exact policy clause being violated
 4. Provide actionable recommendations to fix each violation
+5. Give the line range of the violating code using the line numbers shown
 
 ## Output Format
 Return ONLY a valid JSON object with this exact structure:
@@ -31,7 +35,9 @@ Return ONLY a valid JSON object with this exact structure:
       "title": "short violation title",
       "description": "detailed explanation of what code violates the policy",
       "file_path": "path/to/file.ext",
-      "code_snippet": "the violating code (max 150 chars)",
+      "line_start": 1,
+      "line_end": 1,
+      "code_snippet": "the violating code copied exactly from the file, without line numbers (max 150 chars)",
       "clause_reference": "Section X.Y.Z of the policy",
       "recommendation": "specific fix recommendation"
     }
@@ -41,6 +47,7 @@ Return ONLY a valid JSON object with this exact structure:
 
 IMPORTANT:
 - Only report ACTUAL violations found in the code, not hypothetical issues
+- Every violation must point at code that exists in the file; findings whose code_snippet cannot be found in the file are discarded as unverified
 - If no violations found, return empty violations array and score of 100
 - The compliance_score should be 100 minus penalties (CRITICAL=-25, HIGH=-15, MEDIUM=-8, LOW=-3 per unique rule)
 - Return ONLY valid JSON, no markdown or explanations`
@@ -53,8 +60,10 @@ File: %s
 Severity: %s
 
 Violation description: %s
+Violation location: %s
 
-Code context:
+File contents (%s). Each line is prefixed with its line number and a "|" separator,
+which are NOT part of the file:
 %s
 
 Return ONLY a valid JSON object with this exact structure:
@@ -65,7 +74,9 @@ Return ONLY a valid JSON object with this exact structure:
 }
 
 IMPORTANT:
-- The fix_diff should be in unified diff format with - and + lines
+- The fix_diff should be in unified diff format with @@ -start,count +start,count @@ hunk headers and - and + lines
+- Hunk headers must use the line numbers shown above
+- Context and removed lines must be copied exactly from the file, without the line number prefix
 - confidence should be between 0.0 and 1.0
 - Return ONLY valid JSON, no markdown or explanations`
 
@@ -88,10 +99,11 @@ For each rule, provide:
 5. Category: DATA_PROTECTION, CONSENT, RETENTION, SECURITY, ACCESS_CONTROL, LOGGING, ENCRYPTION, etc.
 6. Check type: FILE_PATTERN, CODE_PATTERN, CONFIG_CHECK, MANUAL
 7. Pattern (regex/glob) if applicable
-8. Recommendations
-9. clause_reference
-10. topic
-11. source_excerpt
+8. applies_to and exclude: lists of file globs (e.g. ["Dockerfile", "*.tf"]) limiting which files the pattern is checked against, if applicable
+9. Recommendations
+10. clause_reference
+11. topic
+12. source_excerpt
 
 ## DOCUMENT TO ANALYZE
 <document_segment>
@@ -135,79 +147,88 @@ Assess each component against:
    - Explainability capabilities (SHAP, LIME, etc.)
    - User notification of AI interaction
 
-Return ONLY a valid JSON array with format:
-[
-  {
-    "name": "component name",
-    "status": "COMPLIANT|REVIEW_REQUIRED|NON_COMPLIANT",
-    "risk_level": "HIGH|MEDIUM|LOW",
-    "issues": 0,
-    "eu_ai_act_risk": "HIGH-RISK|LIMITED-RISK|MINIMAL-RISK",
-    "reasoning": "brief explanation"
-  }
-]`
-
-// BuildCompliancePrompt formats the evaluation prompt with policies and files.
-func BuildCompliancePrompt(policies []map[string]interface{}, files map[string]string, maxFiles, maxCharsPerFile int) string {
-	// Prepare policy rules summary
-	var policiesSummary []map[string]interface{}
-	for _, p := range policies {
-		rulesJSON, ok := p["rules_json"].(string)
-		if !ok || rulesJSON == "" {
-			continue
-		}
-		var parsed map[string]interface{}
-		if err := json.Unmarshal([]byte(rulesJSON), &parsed); err != nil {
-			continue
-		}
-		rules, _ := parsed["rules"].([]interface{})
-		for _, r := range rules {
-			if rule, ok := r.(map[string]interface{}); ok {
-				policiesSummary = append(policiesSummary, map[string]interface{}{
-					"rule_id":          rule["rule_id"],
-					"policy_name":      p["name"],
-					"title":            rule["title"],
-					"description":      rule["description"],
-					"severity":         rule["severity"],
-					"category":         rule["category"],
-					"clause_reference": rule["clause_reference"],
-				})
-			}
-		}
+Return ONLY a valid JSON object with format:
+{
+  "assessments": [
+    {
+      "name": "component name",
+      "status": "COMPLIANT|REVIEW_REQUIRED|NON_COMPLIANT",
+      "risk_level": "HIGH|MEDIUM|LOW",
+      "issues": 0,
+      "eu_ai_act_risk": "HIGH-RISK|LIMITED-RISK|MINIMAL-RISK",
+      "reasoning": "brief explanation"
+    }
+  ]
+}`
+
+// JSONRepairPrompt asks the model to correct output that failed to parse.
+const JSONRepairPrompt = `Your previous response could not be parsed as JSON: %s
+
+Previous response:
+%s
+
+Return the same content as a single valid JSON object matching this JSON Schema:
+%s
+
+Return ONLY valid JSON, no markdown or explanations.`
+
+// ComplianceRule is a policy rule as it appears in the evaluation prompt.
+type ComplianceRule struct {
+	RuleID          string `json:"rule_id"`
+	PolicyName      string `json:"policy_name"`
+	Title           string `json:"title"`
+	Description     string `json:"description"`
+	Severity        string `json:"severity"`
+	Category        string `json:"category,omitempty"`
+	ClauseReference string `json:"clause_reference,omitempty"`
+}
+
+// PromptFile is a source file as it appears in the evaluation prompt.
+type PromptFile struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// BuildCompliancePrompt formats the evaluation prompt with rules and files.
+// Callers are responsible for keeping the prompt within the model's context.
+func BuildCompliancePrompt(rules []ComplianceRule, files []PromptFile) string {
+	if rules == nil {
+		rules = []ComplianceRule{}
 	}
-	if len(policiesSummary) > 30 {
-		policiesSummary = policiesSummary[:30]
+	if files == nil {
+		files = []PromptFile{}
 	}
+	rulesJSON, _ := json.MarshalIndent(rules, "", "  ")
+	filesJSON, _ := json.MarshalIndent(files, "", "  ")
+	return fmt.Sprintf(ComplianceEvaluationPrompt, string(rulesJSON), string(filesJSON))
+}
 
-	// Prepare files summary
-	var filesSummary []map[string]string
-	count := 0
-	for path, content := range files {
-		if count >= maxFiles {
-			break
-		}
-		if len(content) > maxCharsPerFile {
-			content = content[:maxCharsPerFile]
-		}
-		filesSummary = append(filesSummary, map[string]string{
-			"path":    path,
-			"content": content,
-		})
-		count++
+// NumberLines prefixes each line of content with its line number and a "|"
+// separator, as the compliance evaluation prompt expects.
+func NumberLines(content string) string {
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	width := len(fmt.Sprint(len(lines)))
+	var b strings.Builder
+	for i, line := range lines {
+		fmt.Fprintf(&b, "%*d| %s\n", width, i+1, line)
 	}
+	return b.String()
+}
 
-	policiesJSON, _ := json.MarshalIndent(policiesSummary, "", "  ")
-	filesJSON, _ := json.MarshalIndent(filesSummary, "", "  ")
-
-	return fmt.Sprintf(ComplianceEvaluationPrompt, string(policiesJSON), string(filesJSON))
+// EstimateTokens approximates the number of tokens in text at four
+// characters per token, which is close enough for budgeting prompts.
+func EstimateTokens(text string) int {
+	return (len(text) + 3) / 4
 }
 
-// BuildFixPrompt formats the fix generation prompt.
-func BuildFixPrompt(ruleDesc, filePath, severity, violationDesc, fileContent string) string {
-	if len(fileContent) > 4000 {
-		fileContent = fileContent[:4000]
+// BuildFixPrompt formats the fix generation prompt. numberedContent is a
+// window of the file with line-number prefixes; contentRange describes which
+// part of the file it covers.
+func BuildFixPrompt(ruleDesc, filePath, severity, violationDesc, location, contentRange, numberedContent string) string {
+	if len(numberedContent) > 16000 {
+		numberedContent = numberedContent[:16000]
 	}
-	return fmt.Sprintf(FixGenerationPrompt, ruleDesc, filePath, severity, violationDesc, fileContent)
+	return fmt.Sprintf(FixGenerationPrompt, ruleDesc, filePath, severity, violationDesc, location, contentRange, numberedContent)
 }
 
 // BuildPolicyExtractionPrompt formats the policy extraction prompt for a document chunk.
@@ -219,3 +240,9 @@ func BuildPolicyExtractionPrompt(documentChunk string) string {
 func BuildAIGovernancePrompt(modelsSummary string) string {
 	return fmt.Sprintf(AIGovernancePrompt, modelsSummary)
 }
+
+// BuildJSONRepairPrompt formats a re-prompt for output that failed to parse.
+func BuildJSONRepairPrompt(schema *Schema, invalidOutput string, parseErr error) string {
+	schemaJSON, _ := json.MarshalIndent(schema.Definition, "", "  ")
+	return fmt.Sprintf(JSONRepairPrompt, parseErr, invalidOutput, string(schemaJSON))
+}
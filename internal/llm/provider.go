@@ -0,0 +1,155 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// Provider is an LLM backend. Each backend registers itself once with
+// Register, and the client, config and CLI look it up by name.
+type Provider interface {
+	// Name is the identifier used in config (llm_provider).
+	Name() string
+	// Label is a human-readable name for messages and prompts.
+	Label() string
+
+	DefaultModel() string
+	// ValidateModel returns model if the provider accepts it, a replacement
+	// for deprecated models, or the default model.
+	ValidateModel(model string) string
+	// Models lists known models. Providers that accept free-form model
+	// names may return nil.
+	Models() []string
+
+	// DefaultBaseURL is the endpoint used when llm_base_url is not set. An
+	// empty value means the provider has no default and one must be set.
+	DefaultBaseURL() string
+	// APIKeyEnvVar and APIKeyConfigKey name where the API key is read from.
+	APIKeyEnvVar() string
+	APIKeyConfigKey() string
+	// CheckCredentials returns an error if requests cannot be authenticated
+	// with the given API key.
+	CheckCredentials(apiKey string) error
+
+	Generate(ctx context.Context, req *Request) (*Response, error)
+}
+
+// Request is a single prompt sent to a Provider.
+type Request struct {
+	Prompt string
+	// Schema, if set, asks the provider for JSON matching it using the
+	// provider's native structured output.
+	Schema     *Schema
+	Model      string
+	APIKey     string
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+// Response is a provider's reply to a Request.
+type Response struct {
+	Text  string
+	Usage Usage
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Provider{}
+	aliases    = map[string]string{}
+)
+
+// Register makes a provider available by name. It panics if the name is
+// already taken, since that is a programming error.
+func Register(p Provider, alias ...string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, dup := registry[p.Name()]; dup {
+		panic(fmt.Sprintf("llm: provider %q registered twice", p.Name()))
+	}
+	registry[p.Name()] = p
+	for _, a := range alias {
+		aliases[a] = p.Name()
+	}
+}
+
+// LookupProvider returns the provider registered under name or one of its
+// aliases.
+func LookupProvider(name string) (Provider, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	if target, ok := aliases[name]; ok {
+		name = target
+	}
+	p, ok := registry[name]
+	return p, ok
+}
+
+// ProviderFor returns the named provider, falling back to Gemini for empty
+// or unknown names.
+func ProviderFor(name string) Provider {
+	if p, ok := LookupProvider(name); ok {
+		return p
+	}
+	p, _ := LookupProvider(ProviderGemini)
+	return p
+}
+
+// ProviderNames returns the names of all registered providers, sorted.
+func ProviderNames() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// modelCatalog implements the model-related Provider methods from a fixed
+// list of valid and deprecated models.
+type modelCatalog struct {
+	defaultModel string
+	valid        map[string]bool
+	deprecated   map[string]string
+	// freeForm accepts any non-empty model name
+	freeForm bool
+}
+
+func (m modelCatalog) DefaultModel() string {
+	return m.defaultModel
+}
+
+func (m modelCatalog) ValidateModel(model string) string {
+	if model == "" {
+		return m.defaultModel
+	}
+	if m.freeForm || m.valid[model] {
+		return model
+	}
+	if replacement, ok := m.deprecated[model]; ok {
+		return replacement
+	}
+	return m.defaultModel
+}
+
+func (m modelCatalog) Models() []string {
+	var models []string
+	for model := range m.valid {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+	return models
+}
+
+// requireAPIKey is the CheckCredentials implementation for providers that
+// only authenticate with an API key.
+func requireAPIKey(p Provider, apiKey string) error {
+	if apiKey == "" {
+		return ErrMissingAPIKey(p.Name())
+	}
+	return nil
+}
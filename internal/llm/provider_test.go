@@ -0,0 +1,69 @@
+package llm_test
+
+import (
+	"testing"
+
+	"github.com/nerifect/nerifect-cli/internal/llm"
+	"github.com/nerifect/nerifect-cli/internal/llm/llmtest"
+)
+
+// fake is registered once for the test binary, since providers cannot be
+// unregistered and registering a name twice panics.
+var fake = func() *llmtest.FakeProvider {
+	f := &llmtest.FakeProvider{}
+	llm.Register(f, "fake-alias")
+	return f
+}()
+
+func TestRegisterAndLookupProvider(t *testing.T) {
+	for _, name := range []string{"fake", "fake-alias"} {
+		p, ok := llm.LookupProvider(name)
+		if !ok {
+			t.Fatalf("LookupProvider(%q) found nothing", name)
+		}
+		if p != fake {
+			t.Errorf("LookupProvider(%q) = %v, want the registered provider", name, p)
+		}
+	}
+	if _, ok := llm.LookupProvider("no-such-provider"); ok {
+		t.Error("LookupProvider found an unregistered provider")
+	}
+
+	found := false
+	for _, name := range llm.ProviderNames() {
+		if name == "fake-alias" {
+			t.Error("ProviderNames lists an alias")
+		}
+		found = found || name == "fake"
+	}
+	if !found {
+		t.Error("ProviderNames does not list the registered provider")
+	}
+}
+
+func TestRegisterDuplicatePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a provider name twice did not panic")
+		}
+	}()
+	llm.Register(llm.ProviderFor(llm.ProviderOpenAI))
+}
+
+func TestProviderFor(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{llm.ProviderOpenAI, llm.ProviderOpenAI},
+		{llm.ProviderAnthropic, llm.ProviderAnthropic},
+		{llm.ProviderOpenAICompatible, llm.ProviderLocal},
+		{"", llm.ProviderGemini},
+		{"no-such-provider", llm.ProviderGemini},
+	}
+	for _, tt := range tests {
+		if got := llm.ProviderFor(tt.name).Name(); got != tt.want {
+			t.Errorf("ProviderFor(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package llm
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// rateLimiter spaces requests evenly so that no more than rpm are started
+// per minute. It is safe for concurrent use.
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+var (
+	limitersMu sync.Mutex
+	limiters   = map[string]*rateLimiter{}
+)
+
+// limiterFor returns the limiter shared by all clients of a provider, or nil
+// if rpm is not positive. The most recently configured rate wins.
+func limiterFor(provider string, rpm int) *rateLimiter {
+	if rpm <= 0 {
+		return nil
+	}
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+
+	l, ok := limiters[provider]
+	if !ok {
+		l = &rateLimiter{}
+		limiters[provider] = l
+	}
+	l.mu.Lock()
+	l.interval = time.Minute / time.Duration(rpm)
+	l.mu.Unlock()
+	return l
+}
+
+// Wait blocks until the caller may send a request or ctx is done.
+func (l *rateLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	if d := time.Until(slot); d > 0 {
+		return sleep(ctx, d)
+	}
+	return nil
+}
@@ -2,10 +2,16 @@ package llm
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"regexp"
 	"strings"
 )
 
+// ErrInvalidJSON is returned by GenerateJSON when the model's output could
+// not be parsed, even after a repair attempt.
+var ErrInvalidJSON = errors.New("LLM returned invalid JSON")
+
 var jsonFenceRe = regexp.MustCompile("(?s)```(?:json)?\\s*\n?(.*?)```")
 
 // ExtractJSON strips markdown code fences and extracts JSON from LLM response text.
@@ -33,5 +39,8 @@ func ExtractJSON(text string) string {
 // ParseJSONResponse extracts JSON from LLM text and unmarshals into target.
 func ParseJSONResponse(text string, target interface{}) error {
 	jsonStr := ExtractJSON(text)
+	if jsonStr == "" {
+		return fmt.Errorf("response is empty")
+	}
 	return json.Unmarshal([]byte(jsonStr), target)
 }
@@ -0,0 +1,116 @@
+package llm
+
+// Schema describes the JSON object a prompt must produce. Definition is a
+// JSON Schema using only the subset every provider understands: type,
+// properties, required, items, enum and description.
+type Schema struct {
+	Name        string
+	Description string
+	Definition  map[string]interface{}
+}
+
+func objectSchema(properties map[string]interface{}, required ...string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
+
+func arraySchema(items map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"type": "array", "items": items}
+}
+
+func stringSchema() map[string]interface{} {
+	return map[string]interface{}{"type": "string"}
+}
+
+func enumSchema(values ...string) map[string]interface{} {
+	return map[string]interface{}{"type": "string", "enum": values}
+}
+
+func numberSchema() map[string]interface{} {
+	return map[string]interface{}{"type": "number"}
+}
+
+func integerSchema() map[string]interface{} {
+	return map[string]interface{}{"type": "integer"}
+}
+
+var severitySchema = enumSchema("CRITICAL", "HIGH", "MEDIUM", "LOW", "INFO")
+
+// ComplianceSchema matches the output of ComplianceEvaluationPrompt.
+var ComplianceSchema = &Schema{
+	Name:        "compliance_evaluation",
+	Description: "Compliance violations found in the source files",
+	Definition: objectSchema(map[string]interface{}{
+		"violations": arraySchema(objectSchema(map[string]interface{}{
+			"rule_id":          stringSchema(),
+			"policy_name":      stringSchema(),
+			"severity":         severitySchema,
+			"title":            stringSchema(),
+			"description":      stringSchema(),
+			"file_path":        stringSchema(),
+			"line_start":       integerSchema(),
+			"line_end":         integerSchema(),
+			"code_snippet":     stringSchema(),
+			"clause_reference": stringSchema(),
+			"recommendation":   stringSchema(),
+		}, "rule_id", "severity", "title", "description", "file_path", "line_start", "line_end", "code_snippet")),
+		"compliance_score": integerSchema(),
+	}, "violations", "compliance_score"),
+}
+
+// FixSchema matches the output of FixGenerationPrompt.
+var FixSchema = &Schema{
+	Name:        "fix",
+	Description: "A proposed fix for a compliance violation",
+	Definition: objectSchema(map[string]interface{}{
+		"fix_description": stringSchema(),
+		"fix_diff":        stringSchema(),
+		"confidence":      numberSchema(),
+	}, "fix_description", "fix_diff", "confidence"),
+}
+
+// PolicyExtractionSchema matches the output of PolicyExtractionPrompt.
+var PolicyExtractionSchema = &Schema{
+	Name:        "policy_extraction",
+	Description: "Compliance rules extracted from a regulation document",
+	Definition: objectSchema(map[string]interface{}{
+		"regulation_name": stringSchema(),
+		"regulation_type": stringSchema(),
+		"version":         stringSchema(),
+		"summary":         stringSchema(),
+		"rules": arraySchema(objectSchema(map[string]interface{}{
+			"rule_id":          stringSchema(),
+			"title":            stringSchema(),
+			"description":      stringSchema(),
+			"severity":         severitySchema,
+			"category":         stringSchema(),
+			"check_type":       enumSchema("FILE_PATTERN", "CODE_PATTERN", "CONFIG_CHECK", "MANUAL"),
+			"pattern":          stringSchema(),
+			"applies_to":       arraySchema(stringSchema()),
+			"exclude":          arraySchema(stringSchema()),
+			"recommendations":  arraySchema(stringSchema()),
+			"clause_reference": stringSchema(),
+			"topic":            stringSchema(),
+			"source_excerpt":   stringSchema(),
+		}, "rule_id", "title", "description", "severity", "check_type")),
+	}, "regulation_name", "rules"),
+}
+
+// AIGovernanceSchema matches the output of AIGovernancePrompt.
+var AIGovernanceSchema = &Schema{
+	Name:        "ai_governance_assessment",
+	Description: "Governance assessment of detected AI/ML components",
+	Definition: objectSchema(map[string]interface{}{
+		"assessments": arraySchema(objectSchema(map[string]interface{}{
+			"name":           stringSchema(),
+			"status":         enumSchema("COMPLIANT", "REVIEW_REQUIRED", "NON_COMPLIANT"),
+			"risk_level":     enumSchema("HIGH", "MEDIUM", "LOW"),
+			"issues":         integerSchema(),
+			"eu_ai_act_risk": enumSchema("HIGH-RISK", "LIMITED-RISK", "MINIMAL-RISK"),
+			"reasoning":      stringSchema(),
+		}, "name", "status", "risk_level")),
+	}, "assessments"),
+}
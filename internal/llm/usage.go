@@ -0,0 +1,62 @@
+package llm
+
+// Usage counts the tokens consumed by one or more LLM calls.
+type Usage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// Add returns the sum of two usages.
+func (u Usage) Add(o Usage) Usage {
+	return Usage{
+		InputTokens:  u.InputTokens + o.InputTokens,
+		OutputTokens: u.OutputTokens + o.OutputTokens,
+	}
+}
+
+// Call is the usage of a single successful request made by a Client.
+type Call struct {
+	Provider string
+	Model    string
+	Usage    Usage
+}
+
+// Price is the cost of a model in US dollars per million tokens.
+type Price struct {
+	Input  float64 `yaml:"input" json:"input"`
+	Output float64 `yaml:"output" json:"output"`
+}
+
+// Cost estimates the cost of usage at this price.
+func (p Price) Cost(u Usage) float64 {
+	return (float64(u.InputTokens)*p.Input + float64(u.OutputTokens)*p.Output) / 1e6
+}
+
+// DefaultPrices are list prices for the known models at the time of release.
+// They are estimates only; override them with llm_prices in the config.
+var DefaultPrices = map[string]Price{
+	"gemini-2.0-flash":       {Input: 0.10, Output: 0.40},
+	"gemini-2.5-flash":       {Input: 0.30, Output: 2.50},
+	"gemini-2.5-pro":         {Input: 1.25, Output: 10.00},
+	"gemini-3-flash-preview": {Input: 0.50, Output: 3.00},
+	"gemini-3-pro-preview":   {Input: 2.00, Output: 12.00},
+
+	"gpt-4o":       {Input: 2.50, Output: 10.00},
+	"gpt-4o-mini":  {Input: 0.15, Output: 0.60},
+	"gpt-4-turbo":  {Input: 10.00, Output: 30.00},
+	"gpt-4.1":      {Input: 2.00, Output: 8.00},
+	"gpt-4.1-mini": {Input: 0.40, Output: 1.60},
+	"gpt-4.1-nano": {Input: 0.10, Output: 0.40},
+	"o3-mini":      {Input: 1.10, Output: 4.40},
+
+	"claude-sonnet-4-20250514":  {Input: 3.00, Output: 15.00},
+	"claude-3-5-haiku-20241022": {Input: 0.80, Output: 4.00},
+	"claude-opus-4-20250514":    {Input: 15.00, Output: 75.00},
+
+	"anthropic.claude-3-5-sonnet-20240620-v1:0": {Input: 3.00, Output: 15.00},
+	"anthropic.claude-3-5-haiku-20241022-v1:0":  {Input: 0.80, Output: 4.00},
+	"amazon.nova-pro-v1:0":                      {Input: 0.80, Output: 3.20},
+	"amazon.nova-lite-v1:0":                     {Input: 0.06, Output: 0.24},
+	"meta.llama3-1-70b-instruct-v1:0":           {Input: 0.72, Output: 0.72},
+	"mistral.mistral-large-2407-v1:0":           {Input: 2.00, Output: 6.00},
+}
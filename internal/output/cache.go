@@ -0,0 +1,50 @@
+package output
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nerifect/nerifect-cli/internal/store"
+)
+
+// RenderCacheStats prints the LLM response cache summary.
+func RenderCacheStats(stats *store.CacheStats, ttl time.Duration, format Format) {
+	switch format {
+	case FormatJSON:
+		PrintJSON(map[string]interface{}{
+			"stats":     stats,
+			"ttl_hours": int(ttl / time.Hour),
+		})
+	case FormatPlain:
+		fmt.Printf("entries=%d expired=%d hits=%d size=%d ttl=%s\n",
+			stats.Entries, stats.Expired, stats.Hits, stats.SizeBytes, cacheTTL(ttl))
+	default:
+		fmt.Println(HeaderStyle.Render("\nLLM Cache"))
+		fmt.Printf("  %s %d (%d expired)\n", DimStyle.Render("Entries:"), stats.Entries, stats.Expired)
+		fmt.Printf("  %s    %d\n", DimStyle.Render("Hits:"), stats.Hits)
+		fmt.Printf("  %s    %s\n", DimStyle.Render("Size:"), formatBytes(stats.SizeBytes))
+		fmt.Printf("  %s     %s\n", DimStyle.Render("TTL:"), cacheTTL(ttl))
+		if stats.Oldest != nil {
+			fmt.Printf("  %s  %s\n", DimStyle.Render("Oldest:"), stats.Oldest.Format("2006-01-02 15:04"))
+			fmt.Printf("  %s  %s\n", DimStyle.Render("Newest:"), stats.Newest.Format("2006-01-02 15:04"))
+		}
+		fmt.Println()
+	}
+}
+
+func cacheTTL(ttl time.Duration) string {
+	if ttl <= 0 {
+		return "disabled"
+	}
+	return fmt.Sprintf("%dh", int(ttl/time.Hour))
+}
+
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
@@ -0,0 +1,184 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/nerifect/nerifect-cli/internal/compliance"
+	"github.com/nerifect/nerifect-cli/internal/store"
+)
+
+// RenderScanDiff prints the comparison of two scans.
+func RenderScanDiff(d *compliance.ScanDiff, format Format) {
+	switch format {
+	case FormatJSON:
+		PrintJSON(map[string]interface{}{
+			"diff":    d,
+			"summary": buildDiffSummary(d),
+		})
+	case FormatMarkdown:
+		renderDiffMarkdown(d)
+	case FormatPlain:
+		renderDiffPlain(d)
+	default:
+		renderDiffTable(d)
+	}
+}
+
+func buildDiffSummary(d *compliance.ScanDiff) map[string]interface{} {
+	return map[string]interface{}{
+		"base_scan_id":       d.Base.ID,
+		"head_scan_id":       d.Head.ID,
+		"base_score":         d.Base.ComplianceScore,
+		"head_score":         d.Head.ComplianceScore,
+		"score_delta":        d.ScoreDelta,
+		"new_count":          len(d.New),
+		"fixed_count":        len(d.Fixed),
+		"unchanged_count":    len(d.Unchanged),
+		"added_detections":   len(d.AddedDetections),
+		"removed_detections": len(d.RemovedDetections),
+	}
+}
+
+func renderDiffTable(d *compliance.ScanDiff) {
+	summary := fmt.Sprintf(
+		"%s  Scan #%d → #%d\n%s  %s\n\n%s %s   %s %d   %s %d   %s %d",
+		HeaderStyle.Render("Nerifect"), d.Base.ID, d.Head.ID,
+		DimStyle.Render("Target:"), diffTarget(d),
+		BoldStyle.Render("Score:"), scoreChange(d, true),
+		BoldStyle.Render("New:"), len(d.New),
+		BoldStyle.Render("Fixed:"), len(d.Fixed),
+		BoldStyle.Render("Unchanged:"), len(d.Unchanged),
+	)
+	fmt.Println(SummaryBox.Render(summary))
+
+	if len(d.New) > 0 {
+		printViolationTable("New Violations", d.New)
+	}
+	if len(d.Fixed) > 0 {
+		printViolationTable("Fixed Violations", d.Fixed)
+	}
+
+	if len(d.AddedDetections) > 0 || len(d.RemovedDetections) > 0 {
+		fmt.Println(HeaderStyle.Render("AI/ML Detections"))
+		fmt.Println(strings.Repeat("─", 90))
+		for _, det := range d.AddedDetections {
+			fmt.Printf("  %s %-25s %-12s %s\n", HighStyle.Render("+"), Truncate(det.Name, 25), Truncate(det.Type, 12), det.FilePath)
+		}
+		for _, det := range d.RemovedDetections {
+			fmt.Printf("  %s %-25s %-12s %s\n", LowStyle.Render("-"), Truncate(det.Name, 25), Truncate(det.Type, 12), det.FilePath)
+		}
+		fmt.Println()
+	}
+
+	if len(d.New) == 0 && len(d.Fixed) == 0 && len(d.AddedDetections) == 0 && len(d.RemovedDetections) == 0 {
+		fmt.Println(SuccessStyle.Render("  No changes between the two scans."))
+		fmt.Println()
+	}
+}
+
+func renderDiffPlain(d *compliance.ScanDiff) {
+	fmt.Printf("Scan #%d -> #%d | Score: %s | New: %d | Fixed: %d | Unchanged: %d\n",
+		d.Base.ID, d.Head.ID, scoreChange(d, false), len(d.New), len(d.Fixed), len(d.Unchanged))
+	for _, v := range d.New {
+		fmt.Printf("+ [%s] %s - %s in %s\n", v.Severity, v.RuleID, v.Title, Location(v))
+	}
+	for _, v := range d.Fixed {
+		fmt.Printf("- [%s] %s - %s in %s\n", v.Severity, v.RuleID, v.Title, Location(v))
+	}
+	for _, det := range d.AddedDetections {
+		fmt.Printf("+ [AI] %s (%s) in %s\n", det.Name, det.Type, det.FilePath)
+	}
+	for _, det := range d.RemovedDetections {
+		fmt.Printf("- [AI] %s (%s) in %s\n", det.Name, det.Type, det.FilePath)
+	}
+}
+
+func renderDiffMarkdown(d *compliance.ScanDiff) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## Nerifect: scan #%d → #%d\n\n", d.Base.ID, d.Head.ID)
+	fmt.Fprintf(&b, "| | Scan #%d | Scan #%d | Change |\n|---|---|---|---|\n", d.Base.ID, d.Head.ID)
+	fmt.Fprintf(&b, "| Score | %s | %s | %s |\n", scoreValue(d.Base.ComplianceScore), scoreValue(d.Head.ComplianceScore), signedDelta(d.ScoreDelta))
+	fmt.Fprintf(&b, "| Violations | %d | %d | %d new, %d fixed |\n", d.Base.ViolationCount, d.Head.ViolationCount, len(d.New), len(d.Fixed))
+	fmt.Fprintf(&b, "| AI detections | %d | %d | %d added, %d removed |\n", d.Base.AIDetectionCount, d.Head.AIDetectionCount, len(d.AddedDetections), len(d.RemovedDetections))
+
+	writeMarkdownViolations(&b, "New violations", d.New)
+	writeMarkdownViolations(&b, "Fixed violations", d.Fixed)
+
+	if len(d.AddedDetections) > 0 || len(d.RemovedDetections) > 0 {
+		b.WriteString("\n### AI/ML detections\n\n| Change | Framework | Type | File |\n|---|---|---|---|\n")
+		for _, det := range d.AddedDetections {
+			fmt.Fprintf(&b, "| added | %s | %s | `%s` |\n", markdownCell(det.Name), markdownCell(det.Type), markdownCell(det.FilePath))
+		}
+		for _, det := range d.RemovedDetections {
+			fmt.Fprintf(&b, "| removed | %s | %s | `%s` |\n", markdownCell(det.Name), markdownCell(det.Type), markdownCell(det.FilePath))
+		}
+	}
+
+	if len(d.Unchanged) > 0 {
+		fmt.Fprintf(&b, "\nUnchanged violations: %d\n", len(d.Unchanged))
+	}
+	fmt.Print(b.String())
+}
+
+func writeMarkdownViolations(b *strings.Builder, title string, violations []store.Violation) {
+	if len(violations) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n### %s (%d)\n\n| Severity | Rule | Location | Title |\n|---|---|---|---|\n", title, len(violations))
+	for _, v := range violations {
+		fmt.Fprintf(b, "| %s | %s | `%s` | %s |\n", v.Severity, markdownCell(v.RuleID), markdownCell(Location(v)), markdownCell(v.Title))
+	}
+}
+
+// markdownCell escapes text for use inside a markdown table cell.
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.ReplaceAll(s, "\n", " ")
+}
+
+func diffTarget(d *compliance.ScanDiff) string {
+	if d.Base.Target == d.Head.Target {
+		return d.Head.Target
+	}
+	return d.Base.Target + " → " + d.Head.Target
+}
+
+// scoreChange formats the score transition, e.g. "72 → 85 (+13)".
+func scoreChange(d *compliance.ScanDiff, styled bool) string {
+	arrow := "→"
+	if !styled {
+		arrow = "->"
+	}
+	s := fmt.Sprintf("%s %s %s", scoreValue(d.Base.ComplianceScore), arrow, scoreValue(d.Head.ComplianceScore))
+	if d.ScoreDelta == nil {
+		return s
+	}
+	delta := "(" + signedDelta(d.ScoreDelta) + ")"
+	if styled {
+		color := lipgloss.Color("#666666")
+		switch {
+		case *d.ScoreDelta > 0:
+			color = lipgloss.Color("#00CC00")
+		case *d.ScoreDelta < 0:
+			color = lipgloss.Color("#FF0000")
+		}
+		delta = lipgloss.NewStyle().Foreground(color).Render(delta)
+	}
+	return s + " " + delta
+}
+
+func scoreValue(score *int) string {
+	if score == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%d", *score)
+}
+
+func signedDelta(delta *int) string {
+	if delta == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%+d", *delta)
+}
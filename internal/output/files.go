@@ -0,0 +1,90 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nerifect/nerifect-cli/internal/scanner"
+)
+
+// RenderFileList prints the files a scan of root would include. With all set,
+// excluded files and skipped directories are listed too, with the reason.
+func RenderFileList(root string, decisions []scanner.FileDecision, all bool, format Format) {
+	included := 0
+	for _, d := range decisions {
+		if d.Included {
+			included++
+		}
+	}
+	var shown []scanner.FileDecision
+	for _, d := range decisions {
+		if all || d.Included {
+			shown = append(shown, d)
+		}
+	}
+
+	switch format {
+	case FormatJSON:
+		if shown == nil {
+			shown = []scanner.FileDecision{}
+		}
+		PrintJSON(map[string]interface{}{
+			"root":     root,
+			"included": included,
+			"excluded": len(decisions) - included,
+			"files":    shown,
+		})
+	case FormatPlain:
+		for _, d := range shown {
+			switch {
+			case !all:
+				fmt.Println(d.Path)
+			case d.Included:
+				fmt.Printf("+ %s%s\n", displayPath(d), plainReason(d.Reason))
+			default:
+				fmt.Printf("- %s%s\n", displayPath(d), plainReason(d.Reason))
+			}
+		}
+	default:
+		renderFileListTable(root, shown, included, len(decisions)-included, all)
+	}
+}
+
+func renderFileListTable(root string, shown []scanner.FileDecision, included, excluded int, all bool) {
+	fmt.Println(HeaderStyle.Render("\nScanned Files"))
+	fmt.Printf("  %s %s\n", DimStyle.Render("Root:"), root)
+	fmt.Println(strings.Repeat("─", 90))
+	for _, d := range shown {
+		if d.Included {
+			line := "  " + SuccessStyle.Render("+") + " " + d.Path
+			if d.Reason != "" {
+				line += "  " + DimStyle.Render(d.Reason)
+			}
+			fmt.Println(line)
+			continue
+		}
+		fmt.Printf("  %s %s  %s\n", DimStyle.Render("-"), DimStyle.Render(displayPath(d)), DimStyle.Render(d.Reason))
+	}
+	fmt.Println(strings.Repeat("─", 90))
+	summary := fmt.Sprintf("  %d included, %d excluded", included, excluded)
+	if !all && excluded > 0 {
+		summary += DimStyle.Render(" (use --all to see why)")
+	}
+	fmt.Println(summary)
+	fmt.Println()
+}
+
+// displayPath marks directories with a trailing slash.
+func displayPath(d scanner.FileDecision) string {
+	if d.Dir {
+		return d.Path + "/"
+	}
+	return d.Path
+}
+
+func plainReason(reason string) string {
+	if reason == "" {
+		return ""
+	}
+	return " (" + reason + ")"
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/nerifect/nerifect-cli/internal/compliance"
 )
 
 type Format string
@@ -16,6 +17,9 @@ const (
 	FormatJSON  Format = "json"
 	FormatPlain Format = "plain"
 	FormatSARIF Format = "sarif"
+	// FormatMarkdown is only supported by commands meant for pasting into
+	// PRs and issues; others fall back to the table output.
+	FormatMarkdown Format = "markdown"
 )
 
 func ParseFormat(s string) Format {
@@ -26,6 +30,8 @@ func ParseFormat(s string) Format {
 		return FormatPlain
 	case "sarif":
 		return FormatSARIF
+	case "markdown", "md":
+		return FormatMarkdown
 	default:
 		return FormatTable
 	}
@@ -94,6 +100,18 @@ func Truncate(s string, max int) string {
 	return s[:max-3] + "..."
 }
 
+// TruncateLeft shortens s to max characters by dropping its beginning,
+// which keeps the file name and line number of long paths visible.
+func TruncateLeft(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	if max <= 3 {
+		return s[len(s)-max:]
+	}
+	return "..." + s[len(s)-max+3:]
+}
+
 func PrintError(msg string) {
 	fmt.Fprintln(os.Stderr, ErrorStyle.Render("Error: ")+msg)
 }
@@ -102,6 +120,14 @@ func PrintSuccess(msg string) {
 	fmt.Println(SuccessStyle.Render("✓ ") + msg)
 }
 
+// PrintGateFailures explains on stderr why a scan failed its quality gates.
+func PrintGateFailures(failures []compliance.GateFailure) {
+	fmt.Fprintln(os.Stderr, ErrorStyle.Render("✗ Scan failed quality gates:"))
+	for _, f := range failures {
+		fmt.Fprintf(os.Stderr, "  %s %s\n", BoldStyle.Render(f.Gate+":"), f.Reason)
+	}
+}
+
 func PrintWarning(msg string) {
 	fmt.Fprintln(os.Stderr, MediumStyle.Render("Warning: ")+msg)
 }
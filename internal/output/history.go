@@ -0,0 +1,142 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nerifect/nerifect-cli/internal/store"
+)
+
+// HistoryEntry is one scan in a target's history with its active violation
+// counts by severity.
+type HistoryEntry struct {
+	Scan   store.Scan
+	Counts map[store.Severity]int
+}
+
+type historyPoint struct {
+	ScanID         int64     `json:"scan_id"`
+	StartedAt      time.Time `json:"started_at"`
+	Score          *int      `json:"score"`
+	CommitSHA      string    `json:"commit_sha"`
+	ViolationCount int       `json:"violation_count"`
+	Critical       int       `json:"critical"`
+	High           int       `json:"high"`
+	Medium         int       `json:"medium"`
+	Low            int       `json:"low"`
+	Info           int       `json:"info"`
+}
+
+// RenderHistory prints the scans of a target, oldest first.
+func RenderHistory(target string, entries []HistoryEntry, format Format) {
+	switch format {
+	case FormatJSON:
+		series := make([]historyPoint, 0, len(entries))
+		for _, e := range entries {
+			series = append(series, historyPoint{
+				ScanID:         e.Scan.ID,
+				StartedAt:      e.Scan.StartedAt,
+				Score:          e.Scan.ComplianceScore,
+				CommitSHA:      e.Scan.CommitSHA,
+				ViolationCount: e.Scan.ViolationCount,
+				Critical:       e.Counts[store.SeverityCritical],
+				High:           e.Counts[store.SeverityHigh],
+				Medium:         e.Counts[store.SeverityMedium],
+				Low:            e.Counts[store.SeverityLow],
+				Info:           e.Counts[store.SeverityInfo],
+			})
+		}
+		PrintJSON(map[string]interface{}{
+			"target": target,
+			"series": series,
+		})
+	case FormatPlain:
+		for _, e := range entries {
+			fmt.Printf("#%d %s score=%s critical=%d high=%d medium=%d low=%d commit=%s\n",
+				e.Scan.ID, e.Scan.StartedAt.Format(time.RFC3339), scoreValue(e.Scan.ComplianceScore),
+				e.Counts[store.SeverityCritical], e.Counts[store.SeverityHigh],
+				e.Counts[store.SeverityMedium], e.Counts[store.SeverityLow], shortSHA(e.Scan.CommitSHA))
+		}
+	default:
+		renderHistoryTable(target, entries)
+	}
+}
+
+func renderHistoryTable(target string, entries []HistoryEntry) {
+	if len(entries) == 0 {
+		fmt.Println(DimStyle.Render(fmt.Sprintf("  No completed scans found for %s.", target)))
+		return
+	}
+
+	var scores []int
+	for _, e := range entries {
+		if e.Scan.ComplianceScore != nil {
+			scores = append(scores, *e.Scan.ComplianceScore)
+		}
+	}
+
+	fmt.Println(HeaderStyle.Render("\nScan History"))
+	fmt.Printf("  %s %s\n", DimStyle.Render("Target:"), target)
+	if len(scores) > 0 {
+		first, last := scores[0], scores[len(scores)-1]
+		fmt.Printf("  %s %s  %d → %s\n",
+			DimStyle.Render("Score: "), Sparkline(scores), first,
+			BoldStyle.Foreground(ScoreColor(last)).Render(fmt.Sprintf("%d", last)))
+	}
+	fmt.Println(strings.Repeat("─", 90))
+	fmt.Printf("  %-6s %-17s %-6s %-5s %-5s %-5s %-5s %s\n",
+		DimStyle.Render("ID"), DimStyle.Render("DATE"), DimStyle.Render("SCORE"),
+		DimStyle.Render("CRIT"), DimStyle.Render("HIGH"), DimStyle.Render("MED"), DimStyle.Render("LOW"),
+		DimStyle.Render("COMMIT"))
+	fmt.Println(strings.Repeat("─", 90))
+
+	for _, e := range entries {
+		fmt.Printf("  %-6d %-17s %-6s %-5d %-5d %-5d %-5d %s\n",
+			e.Scan.ID,
+			e.Scan.StartedAt.Format("2006-01-02 15:04"),
+			scoreValue(e.Scan.ComplianceScore),
+			e.Counts[store.SeverityCritical],
+			e.Counts[store.SeverityHigh],
+			e.Counts[store.SeverityMedium],
+			e.Counts[store.SeverityLow],
+			shortSHA(e.Scan.CommitSHA),
+		)
+	}
+	fmt.Println()
+}
+
+// sparkRamp is ASCII so the trend renders in any terminal font and in logs.
+var sparkRamp = []rune("_.-=^")
+
+// Sparkline draws values as a row of characters of increasing height scaled
+// between their minimum and maximum.
+func Sparkline(values []int) string {
+	if len(values) == 0 {
+		return ""
+	}
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo = min(lo, v)
+		hi = max(hi, v)
+	}
+	var b strings.Builder
+	for _, v := range values {
+		idx := len(sparkRamp) / 2
+		if hi > lo {
+			idx = (v - lo) * (len(sparkRamp) - 1) / (hi - lo)
+		}
+		b.WriteRune(sparkRamp[idx])
+	}
+	return b.String()
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	if sha == "" {
+		return "-"
+	}
+	return sha
+}
@@ -3,6 +3,7 @@ package output
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/nerifect/nerifect-cli/internal/store"
 )
@@ -16,8 +17,19 @@ type sarifReport struct {
 }
 
 type sarifRun struct {
-	Tool    sarifTool     `json:"tool"`
-	Results []sarifResult `json:"results"`
+	Tool        sarifTool         `json:"tool"`
+	Invocations []sarifInvocation `json:"invocations,omitempty"`
+	Results     []sarifResult     `json:"results"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful        bool                `json:"executionSuccessful"`
+	ToolExecutionNotifications []sarifNotification `json:"toolExecutionNotifications,omitempty"`
+}
+
+type sarifNotification struct {
+	Level   string       `json:"level"`
+	Message sarifMessage `json:"message"`
 }
 
 type sarifTool struct {
@@ -48,11 +60,19 @@ type sarifMessage struct {
 }
 
 type sarifResult struct {
-	RuleID    string           `json:"ruleId"`
-	RuleIndex int              `json:"ruleIndex"`
-	Level     string           `json:"level"`
-	Message   sarifMessage     `json:"message"`
-	Locations []sarifLocation  `json:"locations,omitempty"`
+	RuleID        string             `json:"ruleId"`
+	RuleIndex     int                `json:"ruleIndex"`
+	Level         string             `json:"level"`
+	Message       sarifMessage       `json:"message"`
+	Locations     []sarifLocation    `json:"locations,omitempty"`
+	Suppressions  []sarifSuppression `json:"suppressions,omitempty"`
+	BaselineState string             `json:"baselineState,omitempty"`
+	Properties    map[string]string  `json:"properties,omitempty"`
+}
+
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification,omitempty"`
 }
 
 type sarifLocation struct {
@@ -69,9 +89,11 @@ type sarifArtifactLocation struct {
 }
 
 type sarifRegion struct {
-	StartLine int            `json:"startLine,omitempty"`
-	EndLine   int            `json:"endLine,omitempty"`
-	Snippet   *sarifSnippet  `json:"snippet,omitempty"`
+	StartLine   int           `json:"startLine,omitempty"`
+	StartColumn int           `json:"startColumn,omitempty"`
+	EndLine     int           `json:"endLine,omitempty"`
+	EndColumn   int           `json:"endColumn,omitempty"`
+	Snippet     *sarifSnippet `json:"snippet,omitempty"`
 }
 
 type sarifSnippet struct {
@@ -157,6 +179,17 @@ func RenderSARIF(scan *store.Scan, violations []store.Violation, detections []st
 			Level:     level,
 			Message:   sarifMessage{Text: msg},
 		}
+		// History scan findings were found in a commit, not the current files
+		if v.CommitSHA != "" {
+			result.Message.Text += fmt.Sprintf(" (added in commit %s)", shortSHA(v.CommitSHA))
+			result.Properties = map[string]string{
+				"commitSha":    v.CommitSHA,
+				"commitAuthor": v.CommitAuthor,
+			}
+			if v.CommitDate != nil {
+				result.Properties["commitDate"] = v.CommitDate.Format(time.RFC3339)
+			}
+		}
 
 		if v.FilePath != "" {
 			loc := sarifLocation{
@@ -165,9 +198,10 @@ func RenderSARIF(scan *store.Scan, violations []store.Violation, detections []st
 				},
 			}
 			if v.LineStart > 0 {
-				region := &sarifRegion{StartLine: v.LineStart}
+				region := &sarifRegion{StartLine: v.LineStart, StartColumn: v.ColumnStart}
 				if v.LineEnd > 0 {
 					region.EndLine = v.LineEnd
+					region.EndColumn = v.ColumnEnd
 				}
 				if v.CodeSnippet != "" {
 					region.Snippet = &sarifSnippet{Text: v.CodeSnippet}
@@ -176,6 +210,16 @@ func RenderSARIF(scan *store.Scan, violations []store.Violation, detections []st
 			}
 			result.Locations = []sarifLocation{loc}
 		}
+		if v.Baselined {
+			result.BaselineState = "unchanged"
+		}
+		if v.Unverified {
+			result.Level = "note"
+			result.Message.Text += " (unverified: the reported code was not found in the file)"
+		}
+		if v.Suppressed {
+			result.Suppressions = []sarifSuppression{{Kind: "inSource", Justification: v.SuppressionReason}}
+		}
 
 		results = append(results, result)
 	}
@@ -222,10 +266,27 @@ func RenderSARIF(scan *store.Scan, violations []store.Violation, detections []st
 						Rules:          rules,
 					},
 				},
-				Results: results,
+				Invocations: sarifInvocations(scan),
+				Results:     results,
 			},
 		},
 	}
 
 	PrintJSON(report)
 }
+
+// sarifInvocations reports a degraded scan as an unsuccessful execution so
+// code scanning does not treat its missing results as fixed.
+func sarifInvocations(scan *store.Scan) []sarifInvocation {
+	if !scan.IsDegraded() {
+		return nil
+	}
+	return []sarifInvocation{
+		{
+			ExecutionSuccessful: false,
+			ToolExecutionNotifications: []sarifNotification{
+				{Level: "error", Message: sarifMessage{Text: "Scan degraded: " + scan.DegradedReason}},
+			},
+		},
+	}
+}
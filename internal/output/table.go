@@ -27,9 +27,10 @@ func RenderScanReport(scan *store.Scan, violations []store.Violation, detections
 }
 
 func buildSummary(scan *store.Scan, violations []store.Violation, detections []store.AIDetection) map[string]interface{} {
+	groups := groupViolations(violations)
 	critCount := 0
 	highCount := 0
-	for _, v := range violations {
+	for _, v := range groups.active {
 		switch strings.ToUpper(string(v.Severity)) {
 		case "CRITICAL":
 			critCount++
@@ -43,16 +44,87 @@ func buildSummary(scan *store.Scan, violations []store.Violation, detections []s
 	}
 	return map[string]interface{}{
 		"compliance_score": score,
-		"files_scanned":   scan.FilesScanned,
-		"violation_count":  len(violations),
+		"files_scanned":    scan.FilesScanned,
+		"files_skipped":    scan.FilesSkipped,
+		"files_truncated":  scan.FilesTruncated,
+		"dirs_excluded":    scan.DirsExcluded,
+		"files_ignored":    scan.FilesIgnored,
+		"files_excluded":   scan.FilesExcluded,
+		"files_binary":     scan.FilesBinary,
+		"violation_count":  len(groups.active),
+		"baselined_count":  len(groups.baselined),
+		"suppressed_count": len(groups.suppressed),
+		"unverified_count": len(groups.unverified),
 		"critical_count":   critCount,
 		"high_count":       highCount,
 		"ai_detections":    len(detections),
 		"has_ai_presence":  len(detections) > 0,
+		"degraded":         scan.IsDegraded(),
+	}
+}
+
+// formatFileStats describes the files a scan found but did not scan, by
+// filter, or "" if it scanned every file it found.
+func formatFileStats(scan *store.Scan) string {
+	var parts []string
+	add := func(n int, label string) {
+		if n > 0 {
+			parts = append(parts, fmt.Sprintf(label, formatTokens(n)))
+		}
+	}
+	add(scan.FilesTruncated, "+%s over max_files_per_scan")
+	add(scan.FilesSkipped, "%s skipped")
+	add(scan.DirsExcluded, "%s dirs excluded")
+	add(scan.FilesIgnored, "%s ignored")
+	add(scan.FilesExcluded, "%s excluded by extension")
+	add(scan.FilesBinary, "%s binary")
+	return strings.Join(parts, ", ")
+}
+
+// formatHistory describes how much of the git history a history scan checked.
+func formatHistory(scan *store.Scan) string {
+	return fmt.Sprintf("%d blobs in %d commits", scan.FilesScanned, scan.CommitsScanned)
+}
+
+func formatCoverage(c *store.ScanCoverage) string {
+	s := fmt.Sprintf("%d/%d files, %d/%d rules", c.FilesEvaluated, c.FilesTotal, c.RulesEvaluated, c.RulesTotal)
+	if c.FilesPartial > 0 {
+		s += fmt.Sprintf(" (%d files partly evaluated)", c.FilesPartial)
+	}
+	return s
+}
+
+// violationGroups separates the violations that count toward the score from
+// those already present in the baseline, those silenced by an inline
+// nerifect:ignore comment and LLM findings whose snippet was not found.
+type violationGroups struct {
+	active     []store.Violation
+	baselined  []store.Violation
+	suppressed []store.Violation
+	unverified []store.Violation
+}
+
+func groupViolations(violations []store.Violation) violationGroups {
+	var g violationGroups
+	for _, v := range violations {
+		switch {
+		case v.Suppressed:
+			g.suppressed = append(g.suppressed, v)
+		case v.Baselined:
+			g.baselined = append(g.baselined, v)
+		case v.Unverified:
+			g.unverified = append(g.unverified, v)
+		default:
+			g.active = append(g.active, v)
+		}
 	}
+	return g
 }
 
 func renderScanTable(scan *store.Scan, violations []store.Violation, detections []store.AIDetection) {
+	groups := groupViolations(violations)
+	violations, suppressed := groups.active, groups.suppressed
+
 	// Summary box
 	score := 0
 	if scan.ComplianceScore != nil {
@@ -66,37 +138,89 @@ func renderScanTable(scan *store.Scan, violations []store.Violation, detections
 		duration = fmt.Sprintf(" in %s", d.Round(1e8))
 	}
 
+	violationStr := fmt.Sprintf("%d", len(violations))
+	var hidden []string
+	if len(groups.baselined) > 0 {
+		hidden = append(hidden, fmt.Sprintf("+%d baselined", len(groups.baselined)))
+	}
+	if len(suppressed) > 0 {
+		hidden = append(hidden, fmt.Sprintf("+%d suppressed", len(suppressed)))
+	}
+	if len(groups.unverified) > 0 {
+		hidden = append(hidden, fmt.Sprintf("+%d unverified", len(groups.unverified)))
+	}
+	if len(hidden) > 0 {
+		violationStr += DimStyle.Render(" (" + strings.Join(hidden, ", ") + ")")
+	}
+
+	filesStr := fmt.Sprintf("%d", scan.FilesScanned)
+	if scan.ScanType == store.ScanTypeHistory {
+		filesStr = formatHistory(scan)
+	}
+	if stats := formatFileStats(scan); stats != "" {
+		filesStr += DimStyle.Render(" (" + stats + ")")
+	}
+
 	summary := fmt.Sprintf(
-		"%s  Scan #%d\n%s  %s\n\n%s %s   %s %d   %s %d   %s %d",
+		"%s  Scan #%d\n%s  %s\n\n%s %s   %s %s   %s %s   %s %d",
 		HeaderStyle.Render("Nerifect"), scan.ID,
 		DimStyle.Render("Target:"), scan.Target,
 		BoldStyle.Render("Score:"), scoreStr,
-		BoldStyle.Render("Files:"), scan.FilesScanned,
-		BoldStyle.Render("Violations:"), len(violations),
+		BoldStyle.Render("Files:"), filesStr,
+		BoldStyle.Render("Violations:"), violationStr,
 		BoldStyle.Render("AI Detections:"), len(detections),
 	)
 	if duration != "" {
 		summary += DimStyle.Render(duration)
 	}
+	if scan.Coverage != nil {
+		coverage := "LLM coverage: " + formatCoverage(scan.Coverage)
+		if scan.Coverage.IsComplete() {
+			summary += "\n" + DimStyle.Render(coverage)
+		} else {
+			summary += "\n" + MediumStyle.Render(coverage)
+		}
+	}
+	if scan.Usage != nil {
+		summary += "\n" + DimStyle.Render(FormatUsage(scan.Usage))
+	}
 
 	fmt.Println(SummaryBox.Render(summary))
 
+	if scan.IsDegraded() {
+		PrintWarning("Scan degraded, results are incomplete: " + scan.DegradedReason)
+	}
+
 	// Violations table
 	if len(violations) > 0 {
-		fmt.Println(HeaderStyle.Render("\nCompliance Violations"))
+		printViolationTable("\nCompliance Violations", violations)
+	}
+
+	// Baselined violations already existed when the baseline was created
+	if len(groups.baselined) > 0 {
+		printViolationTable("Baselined Violations", groups.baselined)
+	}
+
+	// Unverified LLM findings may be hallucinated, so they are listed for
+	// review only
+	if len(groups.unverified) > 0 {
+		printViolationTable("Unverified Violations (snippet not found in file)", groups.unverified)
+	}
+
+	// Suppressed violations stay visible together with their justification
+	if len(suppressed) > 0 {
+		fmt.Println(HeaderStyle.Render("Suppressed Violations"))
 		fmt.Println(strings.Repeat("─", 90))
-		fmt.Printf("  %-5s %-10s %-20s %-30s %s\n",
-			DimStyle.Render("ID"), DimStyle.Render("SEVERITY"), DimStyle.Render("RULE"), DimStyle.Render("FILE"), DimStyle.Render("TITLE"))
+		fmt.Printf("  %-5s %-20s %-30s %s\n",
+			DimStyle.Render("ID"), DimStyle.Render("RULE"), DimStyle.Render("FILE"), DimStyle.Render("REASON"))
 		fmt.Println(strings.Repeat("─", 90))
 
-		for _, v := range violations {
-			sevStr := SeverityStyle(string(v.Severity)).Render(fmt.Sprintf("%-10s", v.Severity))
-			fmt.Printf("  %-5d %s %-20s %-30s %s\n",
+		for _, v := range suppressed {
+			fmt.Printf("  %-5d %-20s %-30s %s\n",
 				v.ID,
-				sevStr,
 				Truncate(v.RuleID, 20),
-				Truncate(v.FilePath, 30),
-				Truncate(v.Title, 40),
+				TruncateLeft(Location(v), 30),
+				Truncate(suppressionReason(v), 40),
 			)
 		}
 		fmt.Println()
@@ -129,7 +253,55 @@ func renderScanTable(scan *store.Scan, violations []store.Violation, detections
 	}
 }
 
+func printViolationTable(title string, violations []store.Violation) {
+	fmt.Println(HeaderStyle.Render(title))
+	fmt.Println(strings.Repeat("─", 90))
+	fmt.Printf("  %-5s %-10s %-20s %-30s %s\n",
+		DimStyle.Render("ID"), DimStyle.Render("SEVERITY"), DimStyle.Render("RULE"), DimStyle.Render("FILE"), DimStyle.Render("TITLE"))
+	fmt.Println(strings.Repeat("─", 90))
+
+	for _, v := range violations {
+		sevStr := SeverityStyle(string(v.Severity)).Render(fmt.Sprintf("%-10s", v.Severity))
+		fmt.Printf("  %-5d %s %-20s %-30s %s\n",
+			v.ID,
+			sevStr,
+			Truncate(v.RuleID, 20),
+			TruncateLeft(Location(v), 30),
+			Truncate(v.Title, 40),
+		)
+	}
+	fmt.Println()
+}
+
+// suppressionReason returns the justification given in a nerifect:ignore comment.
+func suppressionReason(v store.Violation) string {
+	if v.SuppressionReason == "" {
+		return "(no reason given)"
+	}
+	return v.SuppressionReason
+}
+
+// Location formats a violation's file and position as path:line:column,
+// followed by the short SHA of the commit for history scan findings.
+func Location(v store.Violation) string {
+	var loc string
+	switch {
+	case v.LineStart > 0 && v.ColumnStart > 0:
+		loc = fmt.Sprintf("%s:%d:%d", v.FilePath, v.LineStart, v.ColumnStart)
+	case v.LineStart > 0:
+		loc = fmt.Sprintf("%s:%d", v.FilePath, v.LineStart)
+	default:
+		loc = v.FilePath
+	}
+	if v.CommitSHA != "" {
+		loc += " @" + shortSHA(v.CommitSHA)
+	}
+	return loc
+}
+
 func renderScanPlain(scan *store.Scan, violations []store.Violation, detections []store.AIDetection) {
+	groups := groupViolations(violations)
+	violations = groups.active
 	score := 0
 	if scan.ComplianceScore != nil {
 		score = *scan.ComplianceScore
@@ -137,9 +309,33 @@ func renderScanPlain(scan *store.Scan, violations []store.Violation, detections
 
 	fmt.Printf("Scan #%d | Target: %s | Score: %d/100 | Files: %d | Violations: %d | AI: %d\n",
 		scan.ID, scan.Target, score, scan.FilesScanned, len(violations), len(detections))
+	if scan.IsDegraded() {
+		fmt.Printf("[DEGRADED] %s\n", scan.DegradedReason)
+	}
+	if scan.ScanType == store.ScanTypeHistory {
+		fmt.Printf("[HISTORY] %s\n", formatHistory(scan))
+	}
+	if stats := formatFileStats(scan); stats != "" {
+		fmt.Printf("[FILES] %s\n", stats)
+	}
+	if scan.Coverage != nil {
+		fmt.Printf("[COVERAGE] %s\n", formatCoverage(scan.Coverage))
+	}
+	if scan.Usage != nil {
+		fmt.Printf("[USAGE] %s\n", FormatUsage(scan.Usage))
+	}
 
 	for _, v := range violations {
-		fmt.Printf("[%s] %s - %s (%s) in %s\n", v.Severity, v.RuleID, v.Title, v.CheckType, v.FilePath)
+		fmt.Printf("[%s] %s - %s (%s) in %s\n", v.Severity, v.RuleID, v.Title, v.CheckType, Location(v))
+	}
+	for _, v := range groups.baselined {
+		fmt.Printf("[BASELINED] %s - %s (%s) in %s\n", v.RuleID, v.Title, v.CheckType, Location(v))
+	}
+	for _, v := range groups.unverified {
+		fmt.Printf("[UNVERIFIED] %s - %s (%s) in %s\n", v.RuleID, v.Title, v.CheckType, Location(v))
+	}
+	for _, v := range groups.suppressed {
+		fmt.Printf("[SUPPRESSED] %s - %s in %s: %s\n", v.RuleID, v.Title, Location(v), suppressionReason(v))
 	}
 	for _, d := range detections {
 		fmt.Printf("[AI] %s (%s) risk=%s eu_ai_act=%s in %s\n", d.Name, d.Type, d.RiskLevel, d.EUAIActRisk, d.FilePath)
@@ -190,19 +386,91 @@ func RenderFix(fix *store.Fix, violation *store.Violation, format Format) {
 	fmt.Printf("  %s %s\n", BoldStyle.Render("Rule:"), violation.RuleID)
 	fmt.Printf("  %s %s\n", BoldStyle.Render("File:"), violation.FilePath)
 	fmt.Printf("  %s %s\n", BoldStyle.Render("Confidence:"), fmt.Sprintf("%.0f%%", fix.Confidence*100))
+	fmt.Printf("  %s %s\n", BoldStyle.Render("Status:"), FixStatusStyle(fix.Status).Render(string(fix.Status)))
+	if fix.ReviewedAt != nil {
+		reviewed := fix.ReviewedAt.Format("2006-01-02 15:04")
+		if fix.ReviewNote != "" {
+			reviewed += " — " + fix.ReviewNote
+		}
+		fmt.Printf("  %s %s\n", BoldStyle.Render("Reviewed:"), reviewed)
+	}
 	fmt.Printf("  %s %s\n", BoldStyle.Render("Description:"), fix.FixDescription)
 	if fix.FixDiff != "" {
 		fmt.Println()
 		fmt.Println(BoldStyle.Render("  Diff:"))
-		for _, line := range strings.Split(fix.FixDiff, "\n") {
-			if strings.HasPrefix(line, "+") {
-				fmt.Println("  " + lipgloss.NewStyle().Foreground(lipgloss.Color("#00CC00")).Render(line))
-			} else if strings.HasPrefix(line, "-") {
-				fmt.Println("  " + lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000")).Render(line))
-			} else {
-				fmt.Println("  " + line)
-			}
+		RenderDiff(fix.FixDiff)
+	}
+	fmt.Println()
+}
+
+// RenderFixes prints the fix review queue.
+func RenderFixes(fixes []store.Fix, format Format) {
+	if format == FormatJSON {
+		PrintJSON(fixes)
+		return
+	}
+
+	if len(fixes) == 0 {
+		fmt.Println(DimStyle.Render("  No fixes found. Use 'nerifect fix <violation-id>' to generate one."))
+		return
+	}
+
+	if format == FormatPlain {
+		for _, f := range fixes {
+			fmt.Printf("#%d [%s] violation=%d scan=%d confidence=%.0f%% %s\n",
+				f.ID, f.Status, f.ViolationID, f.ScanID, f.Confidence*100, f.FixDescription)
 		}
+		return
+	}
+
+	fmt.Println(HeaderStyle.Render("\nFixes"))
+	fmt.Println(strings.Repeat("─", 90))
+	fmt.Printf("  %-5s %-10s %-10s %-6s %-6s %s\n",
+		DimStyle.Render("ID"), DimStyle.Render("STATUS"), DimStyle.Render("VIOLATION"), DimStyle.Render("SCAN"), DimStyle.Render("CONF"), DimStyle.Render("DESCRIPTION"))
+	fmt.Println(strings.Repeat("─", 90))
+
+	for _, f := range fixes {
+		statusStr := FixStatusStyle(f.Status).Render(fmt.Sprintf("%-10s", f.Status))
+		fmt.Printf("  %-5d %s %-10d %-6d %-6s %s\n",
+			f.ID,
+			statusStr,
+			f.ViolationID,
+			f.ScanID,
+			fmt.Sprintf("%.0f%%", f.Confidence*100),
+			Truncate(f.FixDescription, 50),
+		)
 	}
 	fmt.Println()
 }
+
+// FixStatusStyle returns the display style for a fix review status.
+func FixStatusStyle(status store.FixStatus) lipgloss.Style {
+	switch status {
+	case store.FixStatusApproved:
+		return InfoStyle
+	case store.FixStatusApplied:
+		return SuccessStyle
+	case store.FixStatusRejected:
+		return ErrorStyle
+	default:
+		return MediumStyle
+	}
+}
+
+// RenderDiff prints a unified diff with added lines in green and removed lines in red.
+func RenderDiff(diff string) {
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---"):
+			fmt.Println("  " + BoldStyle.Render(line))
+		case strings.HasPrefix(line, "@@"):
+			fmt.Println("  " + InfoStyle.Render(line))
+		case strings.HasPrefix(line, "+"):
+			fmt.Println("  " + lipgloss.NewStyle().Foreground(lipgloss.Color("#00CC00")).Render(line))
+		case strings.HasPrefix(line, "-"):
+			fmt.Println("  " + lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000")).Render(line))
+		default:
+			fmt.Println("  " + line)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nerifect/nerifect-cli/internal/store"
+)
+
+// UsageEntry is the LLM usage of one provider, model and operation with its
+// estimated cost.
+type UsageEntry struct {
+	store.UsageSummary
+	EstimatedCost float64 `json:"estimated_cost_usd"`
+	// Priced is false when the model has no known price
+	Priced bool `json:"priced"`
+}
+
+// RenderUsage prints LLM usage since a point in time; a zero since means all
+// recorded usage.
+func RenderUsage(since time.Time, entries []UsageEntry, total store.UsageTotals, format Format) {
+	switch format {
+	case FormatJSON:
+		var sinceValue *time.Time
+		if !since.IsZero() {
+			sinceValue = &since
+		}
+		if entries == nil {
+			entries = []UsageEntry{}
+		}
+		PrintJSON(map[string]interface{}{
+			"since":   sinceValue,
+			"entries": entries,
+			"total":   total,
+		})
+	case FormatPlain:
+		for _, e := range entries {
+			fmt.Printf("%s %s %s calls=%d input=%d output=%d cost=%s\n",
+				e.Provider, e.Model, e.Operation, e.Calls, e.InputTokens, e.OutputTokens, entryCost(e))
+		}
+		fmt.Printf("total calls=%d input=%d output=%d cost=%s\n",
+			total.Calls, total.InputTokens, total.OutputTokens, totalCost(total))
+	default:
+		renderUsageTable(since, entries, total)
+	}
+}
+
+func renderUsageTable(since time.Time, entries []UsageEntry, total store.UsageTotals) {
+	if len(entries) == 0 {
+		fmt.Println(DimStyle.Render("  No LLM usage recorded."))
+		return
+	}
+
+	fmt.Println(HeaderStyle.Render("\nLLM Usage"))
+	if !since.IsZero() {
+		fmt.Printf("  %s %s\n", DimStyle.Render("Since:"), since.Format("2006-01-02 15:04"))
+	}
+	fmt.Println(strings.Repeat("─", 100))
+	fmt.Printf("  %-13s %-30s %-18s %6s %12s %12s %10s\n",
+		DimStyle.Render("PROVIDER"), DimStyle.Render("MODEL"), DimStyle.Render("OPERATION"),
+		DimStyle.Render("CALLS"), DimStyle.Render("INPUT"), DimStyle.Render("OUTPUT"), DimStyle.Render("COST"))
+	fmt.Println(strings.Repeat("─", 100))
+
+	for _, e := range entries {
+		fmt.Printf("  %-13s %-30s %-18s %6d %12s %12s %10s\n",
+			e.Provider, Truncate(e.Model, 30), e.Operation, e.Calls,
+			formatTokens(e.InputTokens), formatTokens(e.OutputTokens), entryCost(e))
+	}
+
+	fmt.Println(strings.Repeat("─", 100))
+	fmt.Printf("  %-63s %6d %12s %12s %10s\n",
+		BoldStyle.Render("Total"), total.Calls,
+		formatTokens(total.InputTokens), formatTokens(total.OutputTokens), totalCost(total))
+	if total.Unpriced {
+		fmt.Println(DimStyle.Render("\n  Models without a known price are excluded from the cost; set their prices in llm_prices."))
+	}
+	fmt.Println()
+}
+
+// FormatUsage summarizes LLM usage on one line, e.g. for the scan summary.
+func FormatUsage(t *store.UsageTotals) string {
+	calls := "LLM calls"
+	if t.Calls == 1 {
+		calls = "LLM call"
+	}
+	return fmt.Sprintf("%d %s, %s input / %s output tokens, %s",
+		t.Calls, calls, formatTokens(t.InputTokens), formatTokens(t.OutputTokens), totalCost(*t))
+}
+
+func entryCost(e UsageEntry) string {
+	if !e.Priced {
+		return "-"
+	}
+	return formatCost(e.EstimatedCost)
+}
+
+func totalCost(t store.UsageTotals) string {
+	if t.Unpriced {
+		return "≥" + formatCost(t.EstimatedCost)
+	}
+	return "~" + formatCost(t.EstimatedCost)
+}
+
+func formatCost(usd float64) string {
+	if usd > 0 && usd < 0.01 {
+		return fmt.Sprintf("$%.4f", usd)
+	}
+	return fmt.Sprintf("$%.2f", usd)
+}
+
+// formatTokens groups the digits of a token count in thousands.
+func formatTokens(n int) string {
+	s := fmt.Sprint(n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
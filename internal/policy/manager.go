@@ -11,12 +11,14 @@ import (
 
 // Manager handles policy lifecycle: add, list, remove.
 type Manager struct {
+	client  *llm.Client
 	fetcher *Fetcher
 	parser  *Parser
 }
 
 func NewManager(llmClient *llm.Client) *Manager {
 	return &Manager{
+		client:  llmClient,
 		fetcher: NewFetcher(),
 		parser:  NewParser(llmClient),
 	}
@@ -35,6 +37,7 @@ func (m *Manager) AddFromURL(ctx context.Context, url string) (*store.Policy, er
 
 	parsed, err := m.parser.Parse(ctx, text)
 	if err != nil {
+		m.recordUsage(0)
 		return nil, fmt.Errorf("parsing document: %w", err)
 	}
 
@@ -51,8 +54,10 @@ func (m *Manager) AddFromURL(ctx context.Context, url string) (*store.Policy, er
 		len(parsed.Rules),
 	)
 	if err != nil {
+		m.recordUsage(0)
 		return nil, fmt.Errorf("saving policy: %w", err)
 	}
+	m.recordUsage(policy.ID)
 
 	return policy, nil
 }
@@ -70,6 +75,7 @@ func (m *Manager) AddFromFile(ctx context.Context, path string) (*store.Policy,
 
 	parsed, err := m.parser.Parse(ctx, text)
 	if err != nil {
+		m.recordUsage(0)
 		return nil, fmt.Errorf("parsing file: %w", err)
 	}
 
@@ -86,8 +92,10 @@ func (m *Manager) AddFromFile(ctx context.Context, path string) (*store.Policy,
 		len(parsed.Rules),
 	)
 	if err != nil {
+		m.recordUsage(0)
 		return nil, fmt.Errorf("saving policy: %w", err)
 	}
+	m.recordUsage(policy.ID)
 
 	return policy, nil
 }
@@ -101,6 +109,7 @@ func (m *Manager) AddFromText(ctx context.Context, text, sourceURL string) (*sto
 
 	parsed, err := m.parser.Parse(ctx, text)
 	if err != nil {
+		m.recordUsage(0)
 		return nil, fmt.Errorf("parsing document: %w", err)
 	}
 
@@ -117,12 +126,29 @@ func (m *Manager) AddFromText(ctx context.Context, text, sourceURL string) (*sto
 		len(parsed.Rules),
 	)
 	if err != nil {
+		m.recordUsage(0)
 		return nil, fmt.Errorf("saving policy: %w", err)
 	}
+	m.recordUsage(policy.ID)
 
 	return policy, nil
 }
 
+// recordUsage stores the usage of the extraction calls made since the last
+// policy was added. Failed extractions are recorded without a policy.
+func (m *Manager) recordUsage(policyID int64) {
+	for _, c := range m.client.TakeCalls() {
+		store.RecordLLMUsage(&store.LLMUsage{
+			Provider:     c.Provider,
+			Model:        c.Model,
+			Operation:    store.UsageOperationPolicyExtraction,
+			InputTokens:  c.Usage.InputTokens,
+			OutputTokens: c.Usage.OutputTokens,
+			PolicyID:     policyID,
+		})
+	}
+}
+
 // List returns all stored policies.
 func (m *Manager) List() ([]store.Policy, error) {
 	return store.ListPolicies()
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/nerifect/nerifect-cli/internal/llm"
@@ -31,6 +32,8 @@ type ParsedRule struct {
 	ClauseReference string   `json:"clause_reference,omitempty"`
 	Topic           string   `json:"topic,omitempty"`
 	SourceExcerpt   string   `json:"source_excerpt,omitempty"`
+	AppliesTo       []string `json:"applies_to,omitempty"`
+	Exclude         []string `json:"exclude,omitempty"`
 }
 
 // Parser extracts compliance rules from regulation documents using LLM.
@@ -56,7 +59,7 @@ func (p *Parser) Parse(ctx context.Context, text string) (*ParsedPolicy, error)
 	for i, chunk := range chunks {
 		parsed, err := p.extractChunk(ctx, chunk, i)
 		if err != nil {
-			fmt.Fprintf(nil, "")
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
 			continue
 		}
 		results = append(results, parsed)
@@ -83,15 +86,11 @@ func (p *Parser) Parse(ctx context.Context, text string) (*ParsedPolicy, error)
 
 func (p *Parser) extractChunk(ctx context.Context, chunk string, index int) (*ParsedPolicy, error) {
 	prompt := llm.BuildPolicyExtractionPrompt(chunk)
-
-	responseText, err := p.client.GenerateContent(ctx, prompt)
-	if err != nil {
-		return nil, fmt.Errorf("chunk %d LLM call failed: %w", index, err)
-	}
+	key := p.client.CacheKey(llm.PolicyExtractionPromptVersion, "", llm.HashStrings(chunk))
 
 	var parsed ParsedPolicy
-	if err := llm.ParseJSONResponse(responseText, &parsed); err != nil {
-		return nil, fmt.Errorf("chunk %d JSON parse failed: %w", index, err)
+	if err := p.client.GenerateJSONCached(ctx, key, prompt, llm.PolicyExtractionSchema, &parsed); err != nil {
+		return nil, fmt.Errorf("chunk %d extraction failed: %w", index, err)
 	}
 
 	return &parsed, nil
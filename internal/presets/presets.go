@@ -24,6 +24,8 @@ type Rule struct {
 	Pattern         string   `json:"pattern" yaml:"pattern"`
 	Recommendations []string `json:"recommendations" yaml:"recommendations"`
 	ClauseReference string   `json:"clause_reference,omitempty" yaml:"clause_reference,omitempty"`
+	AppliesTo       []string `json:"applies_to,omitempty" yaml:"applies_to,omitempty"`
+	Exclude         []string `json:"exclude,omitempty" yaml:"exclude,omitempty"`
 }
 
 // Preset represents a built-in compliance framework rule pack.
@@ -1,192 +1,517 @@
 package scanner
 
 import (
-	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 
-	"github.com/gobwas/glob"
+	"github.com/nerifect/nerifect-cli/internal/config"
 )
 
-// Binary file extensions to skip when scanning.
-var binaryExts = map[string]bool{
-	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".webp": true,
-	".pdf": true, ".zip": true, ".tar": true, ".gz": true, ".tgz": true,
-	".jar": true, ".exe": true, ".dmg": true, ".woff": true, ".woff2": true,
-	".ttf": true, ".ico": true, ".svg": true, ".mp3": true, ".mp4": true,
-	".mov": true, ".avi": true, ".so": true, ".dylib": true, ".dll": true,
-	".a": true, ".o": true, ".pyc": true, ".class": true, ".wasm": true,
-	".bmp": true, ".eot": true, ".otf": true, ".db": true, ".sqlite": true,
-}
+// binarySniffBytes is how much of a file is checked for NUL bytes to detect
+// binary content, as git does.
+const binarySniffBytes = 8000
 
-// Directories to always skip.
-var skipDirs = map[string]bool{
-	"node_modules": true, ".git": true, "__pycache__": true, ".venv": true,
-	"venv": true, "vendor": true, "dist": true, "build": true, ".next": true,
-	".nuxt": true, "target": true, ".idea": true, ".vscode": true, "coverage": true,
-	".cache": true, ".tox": true, ".mypy_cache": true, ".pytest_cache": true,
-	"env": true, ".env": true, ".terraform": true,
+// LocalFileReader lists and reads the files of a local directory.
+type LocalFileReader struct {
+	rootDir     string
+	opts        FileOptions
+	excludeDirs map[string]bool
+	includeDirs map[string]bool
+	excludeExts map[string]bool
+	includeExts map[string]bool
+	files       []string
+	stats       WalkStats
+	explain     bool
+	decisions   []FileDecision
+	dirRules    map[string]*ignoreRules // ignore rules by directory, for PathFilter
 }
 
-// LocalFileReader implements ai.FileReader for local directories.
-type LocalFileReader struct {
-	rootDir        string
-	files          []string
-	maxFiles       int
-	maxFileSizeKB  int
-	ignorePatterns []glob.Glob
-	allowList      map[string]bool // if non-nil, only include these relative paths
+// FileOptions controls which files a LocalFileReader lists.
+type FileOptions struct {
+	MaxFiles      int
+	MaxFileSizeKB int
+	// RespectGitignore also applies the target's .gitignore files and
+	// .git/info/exclude
+	RespectGitignore bool
+	AllowList        map[string]bool // if non-nil, only include these relative paths
+
+	// Directories are given by name, matching at any depth, or by path
+	// relative to the root. IncludeDirs are walked even if they match
+	// ExcludeDirs.
+	ExcludeDirs []string
+	IncludeDirs []string
+	// Extensions are matched case-insensitively, with or without the dot. If
+	// IncludeExtensions is set, only files with one of them are listed;
+	// files without an extension, such as Dockerfile, are always kept.
+	ExcludeExtensions []string
+	IncludeExtensions []string
 }
 
-func NewLocalFileReader(rootDir string, maxFiles, maxFileSizeKB int) *LocalFileReader {
-	return &LocalFileReader{
-		rootDir:        rootDir,
-		maxFiles:       maxFiles,
-		maxFileSizeKB:  maxFileSizeKB,
-		ignorePatterns: loadIgnorePatterns(rootDir),
+// NewFileOptions returns the file options set in the config, overridden by
+// the settings of repo if it is not nil.
+func NewFileOptions(cfg *config.Config, repo *config.RepoConfig) FileOptions {
+	opts := FileOptions{
+		MaxFiles:          cfg.MaxFilesPerScan,
+		MaxFileSizeKB:     cfg.MaxFileSizeKB,
+		RespectGitignore:  cfg.RespectGitignore,
+		ExcludeDirs:       cfg.ExcludeDirs,
+		IncludeDirs:       cfg.IncludeDirs,
+		ExcludeExtensions: cfg.ExcludeExtensions,
+		IncludeExtensions: cfg.IncludeExtensions,
+	}
+	if opts.ExcludeDirs == nil {
+		opts.ExcludeDirs = config.DefaultExcludeDirs
+	}
+	if opts.ExcludeExtensions == nil {
+		opts.ExcludeExtensions = config.DefaultExcludeExtensions
 	}
+	if repo == nil {
+		return opts
+	}
+	if repo.ExcludeDirs != nil {
+		opts.ExcludeDirs = repo.ExcludeDirs
+	}
+	if repo.IncludeDirs != nil {
+		opts.IncludeDirs = repo.IncludeDirs
+	}
+	if repo.ExcludeExtensions != nil {
+		opts.ExcludeExtensions = repo.ExcludeExtensions
+	}
+	if repo.IncludeExtensions != nil {
+		opts.IncludeExtensions = repo.IncludeExtensions
+	}
+	if repo.MaxFileSizeKB > 0 {
+		opts.MaxFileSizeKB = repo.MaxFileSizeKB
+	}
+	return opts
 }
 
-// NewLocalFileReaderWithAllowList creates a reader that only includes files in the allow list.
-func NewLocalFileReaderWithAllowList(rootDir string, maxFiles, maxFileSizeKB int, allowList map[string]bool) *LocalFileReader {
+// WalkStats counts the files that were found but not scanned, by the filter
+// that left them out.
+type WalkStats struct {
+	// Skipped files exceed max_file_size_kb or could not be read.
+	Skipped int64
+	// Truncated files were left out because max_files_per_scan was reached.
+	Truncated int64
+	// ExcludedDirs counts directories matching exclude_dirs; their files are
+	// not counted.
+	ExcludedDirs int64
+	// Ignored files and directories match an ignore file.
+	Ignored int64
+	// Extension files match exclude_extensions or miss include_extensions.
+	Extension int64
+	// Binary files contain NUL bytes.
+	Binary int64
+}
+
+// Filters that can exclude a file, as reported in FileDecision.Filter.
+const (
+	FilterExcludeDirs = "exclude_dirs"
+	FilterIgnore      = "ignore"
+	FilterExtension   = "extension"
+	FilterBinary      = "binary"
+	FilterSize        = "max_file_size_kb"
+	FilterMaxFiles    = "max_files_per_scan"
+	FilterDiff        = "diff"
+)
+
+// FileDecision records whether a path found by the walk is scanned, and why.
+type FileDecision struct {
+	Path     string `json:"path"`
+	Dir      bool   `json:"dir,omitempty"`
+	Included bool   `json:"included"`
+	Filter   string `json:"filter,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+func NewLocalFileReader(rootDir string, opts FileOptions) *LocalFileReader {
 	return &LocalFileReader{
-		rootDir:        rootDir,
-		maxFiles:       maxFiles,
-		maxFileSizeKB:  maxFileSizeKB,
-		ignorePatterns: loadIgnorePatterns(rootDir),
-		allowList:      allowList,
+		rootDir:     rootDir,
+		opts:        opts,
+		excludeDirs: dirSet(opts.ExcludeDirs),
+		includeDirs: dirSet(opts.IncludeDirs),
+		excludeExts: extSet(opts.ExcludeExtensions),
+		includeExts: extSet(opts.IncludeExtensions),
 	}
 }
 
+// ListFiles returns the relative paths of the files to scan, sorted. The
+// directory tree is walked by concurrent workers; the cap on the number of
+// files is applied after sorting, so the same files are chosen every run.
 func (r *LocalFileReader) ListFiles() ([]string, error) {
 	if r.files != nil {
 		return r.files, nil
 	}
 
-	var files []string
-	err := filepath.Walk(r.rootDir, func(path string, info os.FileInfo, err error) error {
+	var (
+		mu        sync.Mutex
+		files     []string
+		decisions []FileDecision
+		stats     WalkStats
+		wg        sync.WaitGroup
+	)
+	sem := make(chan struct{}, walkWorkers())
+
+	var visit func(relDir string, rules *ignoreRules)
+	visit = func(relDir string, rules *ignoreRules) {
+		defer wg.Done()
+		// The semaphore also bounds the files open for binary detection
+		sem <- struct{}{}
+		defer func() { <-sem }()
+		entries, err := os.ReadDir(filepath.Join(r.rootDir, relDir))
 		if err != nil {
-			return nil // skip errors
+			return // skip errors
 		}
+		rules = rules.child(r.rootDir, relDir, r.ignoreFileNames(relDir, entries))
 
-		// Skip directories
-		if info.IsDir() {
-			name := filepath.Base(path)
-			if skipDirs[name] {
-				return filepath.SkipDir
+		var (
+			found     []string
+			explained []FileDecision
+			dirStats  WalkStats
+		)
+		exclude := func(relPath string, isDir bool, filter, reason string) {
+			switch filter {
+			case FilterExcludeDirs:
+				dirStats.ExcludedDirs++
+			case FilterIgnore:
+				dirStats.Ignored++
+			case FilterExtension:
+				dirStats.Extension++
+			case FilterBinary:
+				dirStats.Binary++
+			case FilterSize:
+				dirStats.Skipped++
 			}
-			// Check ignore patterns against directory paths
-			if len(r.ignorePatterns) > 0 && path != r.rootDir {
-				relDir, _ := filepath.Rel(r.rootDir, path)
-				if relDir != "." {
-					for _, pat := range r.ignorePatterns {
-						if pat.Match(relDir) || pat.Match(relDir+"/") || pat.Match(name) {
-							return filepath.SkipDir
-						}
-					}
-				}
+			if r.explain {
+				explained = append(explained, FileDecision{Path: relPath, Dir: isDir, Filter: filter, Reason: reason})
 			}
-			return nil
 		}
+		for _, entry := range entries {
+			relPath := filepath.ToSlash(filepath.Join(relDir, entry.Name()))
 
-		// Skip binary files
-		ext := strings.ToLower(filepath.Ext(path))
-		if binaryExts[ext] {
-			return nil
+			if entry.IsDir() {
+				if filter, reason := r.skipDir(relPath, entry.Name(), rules); filter != "" {
+					exclude(relPath, true, filter, reason)
+				} else {
+					wg.Add(1)
+					go visit(relPath, rules)
+				}
+				continue
+			}
+
+			filter, reason := r.includeFile(relPath, rules)
+			if filter != "" {
+				exclude(relPath, false, filter, reason)
+				continue
+			}
+
+			// Skip files that are too large
+			if r.opts.MaxFileSizeKB > 0 {
+				info, err := entry.Info()
+				if err != nil {
+					continue
+				}
+				if info.Size() > int64(r.opts.MaxFileSizeKB)*1024 {
+					exclude(relPath, false, FilterSize, fmt.Sprintf("larger than max_file_size_kb (%d)", r.opts.MaxFileSizeKB))
+					continue
+				}
+			}
+			if isBinaryFile(filepath.Join(r.rootDir, relPath)) {
+				exclude(relPath, false, FilterBinary, "binary content")
+				continue
+			}
+			if r.explain {
+				explained = append(explained, FileDecision{Path: relPath, Included: true, Reason: reason})
+			}
+			found = append(found, relPath)
 		}
 
-		// Skip files that are too large
-		if r.maxFileSizeKB > 0 && info.Size() > int64(r.maxFileSizeKB)*1024 {
-			return nil
+		mu.Lock()
+		files = append(files, found...)
+		decisions = append(decisions, explained...)
+		stats.add(dirStats)
+		mu.Unlock()
+	}
+
+	if _, err := os.Stat(r.rootDir); err != nil {
+		return nil, err
+	}
+	wg.Add(1)
+	visit("", nil)
+	wg.Wait()
+
+	sort.Strings(files)
+	if r.opts.MaxFiles > 0 && len(files) > r.opts.MaxFiles {
+		stats.Truncated = int64(len(files) - r.opts.MaxFiles)
+		files = files[:r.opts.MaxFiles]
+	}
+	if files == nil {
+		files = []string{}
+	}
+	r.stats = stats
+	r.files = files
+	r.decisions = decisions
+	return files, nil
+}
+
+// Explain walks the directory like ListFiles and returns a decision for every
+// file and every directory left out of the walk, sorted by path.
+func (r *LocalFileReader) Explain() ([]FileDecision, error) {
+	r.explain = true
+	r.files = nil
+	files, err := r.ListFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	listed := make(map[string]bool, len(files))
+	for _, f := range files {
+		listed[f] = true
+	}
+	decisions := r.decisions
+	for i, d := range decisions {
+		if d.Included && !listed[d.Path] {
+			decisions[i].Included = false
+			decisions[i].Filter = FilterMaxFiles
+			decisions[i].Reason = fmt.Sprintf("over max_files_per_scan (%d)", r.opts.MaxFiles)
 		}
+	}
+	sort.Slice(decisions, func(i, j int) bool { return decisions[i].Path < decisions[j].Path })
+	return decisions, nil
+}
 
-		// Get relative path
-		relPath, err := filepath.Rel(r.rootDir, path)
-		if err != nil {
-			relPath = path
+// PathFilter applies the directory, extension and ignore filters to a path
+// that need not exist in the working tree, such as a file in git history. It
+// returns the filter that excludes the path, or "" if it is selected. Ignore
+// files are read from the working tree. PathFilter is not safe for
+// concurrent use.
+func (r *LocalFileReader) PathFilter(relPath string) string {
+	parts := strings.Split(relPath, "/")
+	dir := ""
+	for _, name := range parts[:len(parts)-1] {
+		sub := path.Join(dir, name)
+		if filter, _ := r.skipDir(sub, name, r.rulesFor(dir)); filter != "" {
+			return filter
 		}
+		dir = sub
+	}
+	filter, _ := r.includeFile(relPath, r.rulesFor(dir))
+	return filter
+}
 
-		// Check allow list (diff-based scanning)
-		if r.allowList != nil && !r.allowList[relPath] {
-			return nil
+// rulesFor returns the ignore rules that apply in a directory, loading the
+// ignore files of the directory and its parents on first use.
+func (r *LocalFileReader) rulesFor(relDir string) *ignoreRules {
+	if rules, ok := r.dirRules[relDir]; ok {
+		return rules
+	}
+	if r.dirRules == nil {
+		r.dirRules = make(map[string]*ignoreRules)
+	}
+	var parent *ignoreRules
+	if relDir != "" {
+		parentDir := path.Dir(relDir)
+		if parentDir == "." {
+			parentDir = ""
 		}
+		parent = r.rulesFor(parentDir)
+	}
+	// Missing ignore files have no rules, so every candidate can be named
+	var names []string
+	if r.opts.RespectGitignore {
+		if relDir == "" {
+			names = append(names, ".git/info/exclude")
+		}
+		names = append(names, ".gitignore")
+	}
+	rules := parent.child(r.rootDir, relDir, append(names, IgnoreFileName))
+	r.dirRules[relDir] = rules
+	return rules
+}
 
-		// Check ignore patterns
-		for _, pat := range r.ignorePatterns {
-			if pat.Match(relPath) || pat.Match(filepath.Base(relPath)) {
-				return nil
+// ignoreFileNames lists the ignore files of a directory, lowest precedence
+// first: git's exclude file and .gitignore when they are respected, then
+// .nerifectignore.
+func (r *LocalFileReader) ignoreFileNames(relDir string, entries []os.DirEntry) []string {
+	var names []string
+	if r.opts.RespectGitignore && relDir == "" {
+		names = append(names, ".git/info/exclude")
+	}
+	for _, name := range []string{".gitignore", IgnoreFileName} {
+		if name == ".gitignore" && !r.opts.RespectGitignore {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.Name() == name && !entry.IsDir() {
+				names = append(names, name)
+				break
 			}
 		}
+	}
+	return names
+}
 
-		files = append(files, relPath)
+// skipDir returns the filter that leaves a directory out of the walk and why,
+// or "" to walk it.
+func (r *LocalFileReader) skipDir(relDir, name string, rules *ignoreRules) (filter, reason string) {
+	if (r.excludeDirs[name] || r.excludeDirs[relDir]) && !r.includeDirs[name] && !r.includeDirs[relDir] {
+		return FilterExcludeDirs, "matches exclude_dirs"
+	}
+	if rule := rules.match(relDir, true); rule != nil && !rule.negate {
+		return FilterIgnore, "ignored by " + rule.String()
+	}
+	return "", ""
+}
 
-		// Cap total files
-		if r.maxFiles > 0 && len(files) >= r.maxFiles {
-			return filepath.SkipAll
-		}
+// includeFile applies the extension, allow list and ignore filters to a file.
+// It returns the filter that excludes the file and why, or no filter and the
+// rule that re-included the file, if any.
+func (r *LocalFileReader) includeFile(relPath string, rules *ignoreRules) (filter, reason string) {
+	ext := fileExt(relPath)
+	if r.excludeExts[ext] {
+		return FilterExtension, "matches exclude_extensions"
+	}
+	if len(r.includeExts) > 0 && ext != "" && !r.includeExts[ext] {
+		return FilterExtension, "not in include_extensions"
+	}
 
-		return nil
-	})
+	// Check allow list (diff-based scanning)
+	if r.opts.AllowList != nil && !r.opts.AllowList[relPath] {
+		return FilterDiff, "not changed since the diff base"
+	}
 
-	r.files = files
-	return files, err
+	if rule := rules.match(relPath, false); rule != nil {
+		if rule.negate {
+			return "", "re-included by " + rule.String()
+		}
+		return FilterIgnore, "ignored by " + rule.String()
+	}
+	return "", ""
 }
 
-func (r *LocalFileReader) ReadFile(path string) (string, error) {
-	fullPath := filepath.Join(r.rootDir, path)
-	data, err := os.ReadFile(fullPath)
+func (s *WalkStats) add(o WalkStats) {
+	s.Skipped += o.Skipped
+	s.Truncated += o.Truncated
+	s.ExcludedDirs += o.ExcludedDirs
+	s.Ignored += o.Ignored
+	s.Extension += o.Extension
+	s.Binary += o.Binary
+}
+
+// isBinaryFile reports whether the start of a file contains a NUL byte.
+// Files that cannot be read are left to fail when they are read in full.
+func isBinaryFile(path string) bool {
+	f, err := os.Open(path)
 	if err != nil {
-		return "", err
+		return false
 	}
-	return string(data), nil
+	defer f.Close()
+	buf := make([]byte, binarySniffBytes)
+	n, _ := io.ReadFull(f, buf)
+	return bytes.IndexByte(buf[:n], 0) >= 0
 }
 
-// ReadFilesContents reads contents of all text files up to limits.
-func (r *LocalFileReader) ReadFilesContents() (map[string]string, error) {
-	files, err := r.ListFiles()
-	if err != nil {
-		return nil, err
+// fileExt returns the lower-case extension of a file. The leading dot of a
+// dotfile such as .gitignore does not start an extension.
+func fileExt(path string) string {
+	return strings.ToLower(filepath.Ext(strings.TrimPrefix(filepath.Base(path), ".")))
+}
+
+// dirSet normalizes directory names and paths for lookup.
+func dirSet(dirs []string) map[string]bool {
+	set := make(map[string]bool, len(dirs))
+	for _, d := range dirs {
+		if d = strings.Trim(filepath.ToSlash(d), "/"); d != "" {
+			set[d] = true
+		}
 	}
+	return set
+}
 
-	contents := make(map[string]string)
-	for _, path := range files {
-		content, err := r.ReadFile(path)
-		if err != nil {
+// extSet normalizes extensions to lower case with a leading dot.
+func extSet(exts []string) map[string]bool {
+	set := make(map[string]bool, len(exts))
+	for _, e := range exts {
+		e = strings.ToLower(strings.TrimSpace(e))
+		if e == "" {
 			continue
 		}
-		contents[path] = content
+		if !strings.HasPrefix(e, ".") {
+			e = "." + e
+		}
+		set[e] = true
 	}
-	return contents, nil
+	return set
 }
 
-// IsTextFile checks if a file extension indicates a text file.
-func IsTextFile(path string) bool {
-	ext := strings.ToLower(filepath.Ext(path))
-	return !binaryExts[ext]
+// Stats returns the counts of files left out of the scan. It is complete
+// once ListFiles and StreamFiles have returned.
+func (r *LocalFileReader) Stats() WalkStats {
+	stats := r.stats
+	stats.Skipped = atomic.LoadInt64(&r.stats.Skipped)
+	return stats
 }
 
-// loadIgnorePatterns reads a .nerifectignore file and compiles glob patterns.
-func loadIgnorePatterns(rootDir string) []glob.Glob {
-	f, err := os.Open(filepath.Join(rootDir, ".nerifectignore"))
+func (r *LocalFileReader) ReadFile(path string) (string, error) {
+	fullPath := filepath.Join(r.rootDir, path)
+	data, err := os.ReadFile(fullPath)
 	if err != nil {
-		return nil
+		return "", err
 	}
-	defer f.Close()
+	return string(data), nil
+}
 
-	var patterns []glob.Glob
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-		// Strip trailing slash for directory patterns — match both with and without
-		g, err := glob.Compile(strings.TrimRight(line, "/"))
-		if err != nil {
-			continue
+// StreamFiles reads paths with a pool of workers and calls fn from the
+// workers with each file's index in paths and its content. fn must be safe
+// for concurrent use. Only the files being processed are held in memory.
+// Files that cannot be read are counted as skipped.
+func (r *LocalFileReader) StreamFiles(ctx context.Context, paths []string, workers int, fn func(i int, path, content string)) error {
+	if workers <= 0 {
+		workers = walkWorkers()
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				content, err := r.ReadFile(paths[i])
+				if err != nil {
+					atomic.AddInt64(&r.stats.Skipped, 1)
+					continue
+				}
+				fn(i, paths[i], content)
+			}
+		}()
+	}
+
+	var err error
+feed:
+	for i := range paths {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			err = ctx.Err()
+			break feed
 		}
-		patterns = append(patterns, g)
 	}
-	return patterns
+	close(jobs)
+	wg.Wait()
+	return err
+}
+
+// walkWorkers is the number of concurrent workers used to walk and read files.
+func walkWorkers() int {
+	return runtime.NumCPU() * 2
 }
@@ -0,0 +1,238 @@
+package scanner
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// GitAuth holds HTTPS credentials for git commands against a remote. They
+// are passed to git as an http.extraHeader in the environment, so they never
+// appear in URLs, command lines or git's output.
+type GitAuth struct {
+	// scope is the URL prefix the credentials are sent to, e.g.
+	// "https://gitlab.com/", so redirects to other hosts do not receive them
+	scope    string
+	user     string
+	password string
+}
+
+// header returns the HTTP basic authorization header for the credentials.
+func (a *GitAuth) header() string {
+	return "Authorization: Basic " + base64.StdEncoding.EncodeToString([]byte(a.user+":"+a.password))
+}
+
+// gitEnv returns the environment for git commands against a remote. Git
+// never prompts for credentials, so a missing token fails instead of hanging;
+// SSH uses the keys of the running SSH agent. The credentials are added after
+// any GIT_CONFIG_* settings already in the environment.
+func gitEnv(auth *GitAuth) []string {
+	env := append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if os.Getenv("GIT_SSH_COMMAND") == "" {
+		env = append(env, "GIT_SSH_COMMAND=ssh -o BatchMode=yes")
+	}
+	if auth != nil {
+		n := 0
+		kept := env[:0]
+		for _, kv := range env {
+			if count, ok := strings.CutPrefix(kv, "GIT_CONFIG_COUNT="); ok {
+				n, _ = strconv.Atoi(count)
+				continue
+			}
+			kept = append(kept, kv)
+		}
+		env = append(kept,
+			fmt.Sprintf("GIT_CONFIG_COUNT=%d", n+1),
+			fmt.Sprintf("GIT_CONFIG_KEY_%d=http.%s.extraHeader", n, auth.scope),
+			fmt.Sprintf("GIT_CONFIG_VALUE_%d=%s", n, auth.header()),
+		)
+	}
+	return env
+}
+
+// minAuthGitVersion is the first git release that reads GIT_CONFIG_COUNT,
+// which credentials are passed through.
+var minAuthGitVersion = [2]int{2, 31}
+
+var (
+	gitVersionOnce sync.Once
+	gitVersionErr  error
+)
+
+// checkGitVersion returns an error if the installed git is too old to
+// receive credentials through the environment. Older versions would ignore
+// them and fail with a misleading authentication error.
+func checkGitVersion() error {
+	gitVersionOnce.Do(func() {
+		out, err := exec.Command("git", "version").Output()
+		if err != nil {
+			gitVersionErr = fmt.Errorf("running git version: %w", err)
+			return
+		}
+		version := strings.TrimSpace(string(out))
+		if !gitVersionAtLeast(version, minAuthGitVersion) {
+			gitVersionErr = fmt.Errorf("authenticated clones require git %d.%d or later, found %q",
+				minAuthGitVersion[0], minAuthGitVersion[1], version)
+		}
+	})
+	return gitVersionErr
+}
+
+// gitVersionAtLeast reports whether the output of "git version", e.g.
+// "git version 2.39.2 (Apple Git-143)", is at least min. Unrecognized output
+// is accepted, so unusual builds are not refused.
+func gitVersionAtLeast(version string, min [2]int) bool {
+	fields := strings.Fields(version)
+	if len(fields) < 3 {
+		return true
+	}
+	parts := strings.SplitN(fields[2], ".", 3)
+	if len(parts) < 2 {
+		return true
+	}
+	major, err1 := strconv.Atoi(parts[0])
+	minor, err2 := strconv.Atoi(parts[1])
+	if err1 != nil || err2 != nil {
+		return true
+	}
+	if major != min[0] {
+		return major > min[0]
+	}
+	return minor >= min[1]
+}
+
+// urlUserinfoRe matches the scheme and userinfo of a URL.
+var urlUserinfoRe = regexp.MustCompile(`([A-Za-z][A-Za-z0-9+.-]*)://([^/@\s]*)@`)
+
+// redactUserinfo replaces the userinfo of every URL in s with mask. A token
+// can be given as the user name alone (https://TOKEN@host/...), so the whole
+// userinfo is a secret, except the user name of SSH URLs, which selects the
+// account SSH authenticates as.
+func redactUserinfo(s, mask string) string {
+	return urlUserinfoRe.ReplaceAllStringFunc(s, func(m string) string {
+		sub := urlUserinfoRe.FindStringSubmatch(m)
+		scheme, userinfo := sub[1], sub[2]
+		if strings.Contains(strings.ToLower(scheme), "ssh") {
+			user, _, hasPassword := strings.Cut(userinfo, ":")
+			if !hasPassword {
+				return m
+			}
+			return scheme + "://" + user + "@"
+		}
+		if mask == "" {
+			return scheme + "://"
+		}
+		return scheme + "://" + mask + "@"
+	})
+}
+
+// RedactURL removes the credentials from any URL in s, so it can be shown or
+// stored.
+func RedactURL(s string) string {
+	return redactUserinfo(s, "")
+}
+
+// scrubSecrets removes credentials from git output and errors: the userinfo
+// of any URL, and auth's password and header wherever they appear.
+func scrubSecrets(s string, auth *GitAuth) string {
+	s = redactUserinfo(s, "***")
+	if auth != nil {
+		s = strings.ReplaceAll(s, auth.header(), "Authorization: ***")
+		if auth.password != "" {
+			s = strings.ReplaceAll(s, auth.password, "***")
+		}
+	}
+	return s
+}
+
+// runGit runs a git command against a remote with its credentials, returning
+// an error with the scrubbed output if it fails.
+func runGit(ctx context.Context, auth *GitAuth, args ...string) error {
+	if auth != nil {
+		if err := checkGitVersion(); err != nil {
+			return err
+		}
+	}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = gitEnv(auth)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(scrubSecrets(string(output), auth)); msg != "" {
+			return fmt.Errorf("%s: %w", msg, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// CloneRepo clones a remote repo to a temp directory, keeping depth commits
+// of history; zero clones the full history. auth may be nil.
+// Returns the temp dir path and a cleanup function.
+func CloneRepo(ctx context.Context, url string, auth *GitAuth, branch string, depth int) (string, func(), error) {
+	tmpDir, err := os.MkdirTemp("", "nerifect-scan-*")
+	if err != nil {
+		return "", nil, fmt.Errorf("creating temp dir: %w", err)
+	}
+	cleanup := func() { os.RemoveAll(tmpDir) }
+
+	args := []string{"clone"}
+	if depth > 0 {
+		args = append(args, fmt.Sprintf("--depth=%d", depth))
+	}
+	if branch != "" {
+		args = append(args, "--branch", branch)
+	}
+	args = append(args, "--", url, tmpDir)
+
+	if err := runGit(ctx, auth, args...); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("git clone failed: %w", err)
+	}
+
+	return tmpDir, cleanup, nil
+}
+
+// CloneRepoAtCommit fetches a single commit of a repo into a temp directory.
+// If sha is empty the default branch is cloned instead. auth may be nil.
+// Returns the temp dir path and a cleanup function.
+func CloneRepoAtCommit(ctx context.Context, url string, auth *GitAuth, sha string) (string, func(), error) {
+	if sha == "" {
+		return CloneRepo(ctx, url, auth, "", 1)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "nerifect-scan-*")
+	if err != nil {
+		return "", nil, fmt.Errorf("creating temp dir: %w", err)
+	}
+	cleanup := func() { os.RemoveAll(tmpDir) }
+
+	steps := [][]string{
+		{"init", "--quiet"},
+		{"fetch", "--quiet", "--depth=1", "--", url, sha},
+		{"checkout", "--quiet", "FETCH_HEAD"},
+	}
+	for _, args := range steps {
+		if err := runGit(ctx, auth, append([]string{"-C", tmpDir}, args...)...); err != nil {
+			cleanup()
+			return "", nil, fmt.Errorf("git %s failed: %w", args[0], err)
+		}
+	}
+
+	return tmpDir, cleanup, nil
+}
+
+// GetCloneCommitSHA returns the HEAD commit SHA of a cloned repo.
+func GetCloneCommitSHA(dir string) string {
+	cmd := exec.Command("git", "-C", dir, "rev-parse", "HEAD")
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
@@ -0,0 +1,64 @@
+package scanner
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGitEnvKeepsExistingConfig(t *testing.T) {
+	t.Setenv("GIT_CONFIG_COUNT", "2")
+	t.Setenv("GIT_CONFIG_KEY_0", "core.autocrlf")
+	t.Setenv("GIT_CONFIG_VALUE_0", "false")
+	t.Setenv("GIT_CONFIG_KEY_1", "http.proxy")
+	t.Setenv("GIT_CONFIG_VALUE_1", "http://proxy:3128")
+
+	auth := &GitAuth{scope: "https://gitlab.com/", user: "oauth2", password: "secret"}
+	vars := map[string][]string{}
+	for _, kv := range gitEnv(auth) {
+		key, value, _ := strings.Cut(kv, "=")
+		vars[key] = append(vars[key], value)
+	}
+
+	want := map[string]string{
+		"GIT_CONFIG_COUNT":   "3",
+		"GIT_CONFIG_KEY_0":   "core.autocrlf",
+		"GIT_CONFIG_KEY_1":   "http.proxy",
+		"GIT_CONFIG_KEY_2":   "http.https://gitlab.com/.extraHeader",
+		"GIT_CONFIG_VALUE_2": auth.header(),
+	}
+	for key, value := range want {
+		if got := vars[key]; len(got) != 1 || got[0] != value {
+			t.Errorf("%s = %q, want [%q]", key, got, value)
+		}
+	}
+}
+
+func TestGitEnvWithoutAuth(t *testing.T) {
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+	for _, kv := range gitEnv(nil) {
+		if strings.HasPrefix(kv, "GIT_CONFIG_COUNT=") && kv != "GIT_CONFIG_COUNT=1" {
+			t.Errorf("gitEnv(nil) changed %s", kv)
+		}
+	}
+}
+
+func TestGitVersionAtLeast(t *testing.T) {
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{"git version 2.31.0", true},
+		{"git version 2.39.2 (Apple Git-143)", true},
+		{"git version 2.45.1.windows.1", true},
+		{"git version 3.0", true},
+		{"git version 2.30.9", false},
+		{"git version 2.24.3 (Apple Git-128)", false},
+		{"git version 1.8.3.1", false},
+		{"unexpected", true},
+	}
+	for _, tt := range tests {
+		if got := gitVersionAtLeast(tt.version, minAuthGitVersion); got != tt.want {
+			t.Errorf("gitVersionAtLeast(%q) = %v, want %v", tt.version, got, tt.want)
+		}
+	}
+}
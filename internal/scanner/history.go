@@ -0,0 +1,306 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nerifect/nerifect-cli/internal/compliance"
+	"github.com/nerifect/nerifect-cli/internal/config"
+	"github.com/nerifect/nerifect-cli/internal/store"
+)
+
+// commitMarker starts the line git log prints for each commit and separates
+// its fields. A NUL byte cannot occur in the text of a diff, so it cannot be
+// confused with a changed line.
+const commitMarker = "\x00"
+
+// commitFormat prints the commit line: SHA, author and date, each preceded by
+// commitMarker.
+const commitFormat = "%x00%H%x00%an <%ae>%x00%aI"
+
+// historyScanner checks the lines added by each commit of a repository
+// against pattern rules. Each file version (blob) is checked once, in the
+// first commit that produced it.
+type historyScanner struct {
+	reader   *LocalFileReader
+	checker  *compliance.PatternChecker
+	maxBytes int
+
+	seenBlobs  map[string]bool
+	addedPaths []string                      // paths in the order they were first added
+	pathCommit map[string]*compliance.Commit // commit that first added each path
+	violations []compliance.ViolationResult  // code pattern matches, capped per rule
+
+	commits int // commits walked
+	blobs   int // file versions checked
+	skipped int // file versions whose added lines exceed max_file_size_kb
+}
+
+// addedLines collects the lines a commit added to one file.
+type addedLines struct {
+	commit   *compliance.Commit
+	path     string
+	blob     string
+	lines    []string
+	lineNums []int // line number of each added line in the new file
+	size     int
+	tooLarge bool
+}
+
+// runHistoryScan checks the lines added by every commit of the git repository
+// in dir against the pattern rules of policies. LLM evaluation and AI
+// detection only apply to the current files, so history scans skip them.
+func runHistoryScan(ctx context.Context, scan *store.Scan, dir string, cfg *config.Config, opts ScanOptions, policies []store.Policy) (*ScanResult, error) {
+	if len(policies) == 0 {
+		fmt.Fprintf(os.Stderr, "warning: no policies loaded, use 'nerifect policy add' to add policies\n")
+	}
+
+	fileOpts := NewFileOptions(cfg, opts.Repo)
+	reader := NewLocalFileReader(dir, fileOpts)
+	checker := compliance.NewPatternChecker(store.ExtractRulesFromPolicies(policies), cfg.MaxMatchesPerRule)
+	h := newHistoryScanner(reader, checker, fileOpts.MaxFileSizeKB)
+	if err := h.run(ctx, dir, opts.Since); err != nil {
+		store.FailScan(scan.ID)
+		return nil, fmt.Errorf("scanning history: %w", err)
+	}
+
+	patternViolations := append(h.pathViolations(), h.violations...)
+	patternViolations = compliance.ApplyBaseline(patternViolations, opts.Baseline)
+	for _, ruleID := range checker.CappedRules() {
+		fmt.Fprintf(os.Stderr, "warning: rule %s reached the limit of %d matches, further matches were not reported\n", ruleID, cfg.MaxMatchesPerRule)
+	}
+
+	var allViolations []store.Violation
+	for _, v := range patternViolations {
+		viol, err := saveViolation(scan.ID, v, "PATTERN")
+		if err == nil {
+			allViolations = append(allViolations, *viol)
+		}
+	}
+
+	score, activeCount := scoreViolations(allViolations)
+	store.SetScanFileStats(scan.ID, store.FileStats{FilesSkipped: h.skipped})
+	store.SetScanCommits(scan.ID, h.commits)
+	store.CompleteScan(scan.ID, &score, h.blobs, activeCount, 0, GetCloneCommitSHA(dir))
+
+	return finishScan(cfg, scan.ID, allViolations, nil), nil
+}
+
+func newHistoryScanner(reader *LocalFileReader, checker *compliance.PatternChecker, maxFileSizeKB int) *historyScanner {
+	return &historyScanner{
+		reader:     reader,
+		checker:    checker,
+		maxBytes:   maxFileSizeKB * 1024,
+		seenBlobs:  make(map[string]bool),
+		pathCommit: make(map[string]*compliance.Commit),
+	}
+}
+
+// run walks the commits of the repository in dir, oldest first. If since is
+// set, only commits after it are walked.
+func (h *historyScanner) run(ctx context.Context, dir, since string) error {
+	rev := "HEAD"
+	if since != "" {
+		check := exec.CommandContext(ctx, "git", "rev-parse", "--verify", "--quiet", since+"^{commit}")
+		check.Dir = dir
+		if err := check.Run(); err != nil {
+			return fmt.Errorf("unknown git ref %q", since)
+		}
+		rev = since + "..HEAD"
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "-c", "core.quotePath=false", "log",
+		"--reverse", "--no-merges", "--no-renames", "--no-color", "--no-ext-diff", "--no-textconv",
+		"--raw", "--no-abbrev", "-p", "--unified=0",
+		"--format="+commitFormat,
+		rev, "--")
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("running git log: %w", err)
+	}
+
+	if err := h.parse(bufio.NewReaderSize(stdout, 64*1024)); err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return err
+	}
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("git log failed: %s: %w", strings.TrimSpace(stderr.String()), err)
+	}
+	return nil
+}
+
+// parse reads the output of git log with raw and patch output. For each
+// commit, git prints the commit line, one raw line per changed file with its
+// new blob, then the patches.
+func (h *historyScanner) parse(r *bufio.Reader) error {
+	var (
+		commit *compliance.Commit
+		blobs  map[string]string
+		file   *addedLines
+		inHunk bool
+		next   int
+	)
+	for {
+		line, err := r.ReadString('\n')
+		if line == "" && err != nil {
+			if err == io.EOF {
+				break
+			}
+			return err
+		}
+		line = strings.TrimSuffix(line, "\n")
+
+		switch {
+		case strings.HasPrefix(line, commitMarker):
+			h.checkFile(file)
+			file = nil
+			parts := strings.SplitN(line[len(commitMarker):], commitMarker, 3)
+			if len(parts) != 3 {
+				return fmt.Errorf("unexpected git log output: %q", line)
+			}
+			date, _ := time.Parse(time.RFC3339, parts[2])
+			commit = &compliance.Commit{SHA: parts[0], Author: parts[1], Date: date}
+			blobs = make(map[string]string)
+			h.commits++
+
+		case strings.HasPrefix(line, "diff --git "):
+			h.checkFile(file)
+			file = &addedLines{commit: commit}
+			inHunk = false
+
+		case file == nil:
+			// Raw line: ":old_mode new_mode old_blob new_blob status\tpath"
+			if !strings.HasPrefix(line, ":") {
+				continue
+			}
+			meta, path, ok := strings.Cut(line, "\t")
+			fields := strings.Fields(meta)
+			if !ok || len(fields) < 5 {
+				continue
+			}
+			blobs[path] = fields[3]
+			if fields[4] == "A" && h.pathCommit[path] == nil {
+				h.pathCommit[path] = commit
+				h.addedPaths = append(h.addedPaths, path)
+			}
+
+		case !inHunk && strings.HasPrefix(line, "+++ "):
+			if path := line[len("+++ "):]; path != "/dev/null" {
+				// Git ends the line with a tab when the path has a space
+				file.path = strings.TrimPrefix(strings.TrimSuffix(path, "\t"), "b/")
+				file.blob = blobs[file.path]
+			}
+
+		case strings.HasPrefix(line, "@@ "):
+			inHunk = true
+			next = hunkStart(line)
+
+		case inHunk && strings.HasPrefix(line, "+"):
+			file.add(next, line[1:], h.maxBytes)
+			next++
+		}
+	}
+	h.checkFile(file)
+	return nil
+}
+
+func (f *addedLines) add(lineNum int, line string, maxBytes int) {
+	if f.tooLarge {
+		return
+	}
+	f.size += len(line) + 1
+	if maxBytes > 0 && f.size > maxBytes {
+		f.tooLarge = true
+		f.lines, f.lineNums = nil, nil
+		return
+	}
+	f.lines = append(f.lines, line)
+	f.lineNums = append(f.lineNums, lineNum)
+}
+
+// checkFile checks the lines a commit added to a file, unless the file is
+// filtered out or its new version was already checked.
+func (h *historyScanner) checkFile(f *addedLines) {
+	if f == nil || f.path == "" || (len(f.lines) == 0 && !f.tooLarge) {
+		return
+	}
+	if h.reader.PathFilter(f.path) != "" {
+		return
+	}
+	if f.blob != "" {
+		if h.seenBlobs[f.blob] {
+			return
+		}
+		h.seenBlobs[f.blob] = true
+	}
+	if f.tooLarge {
+		h.skipped++
+		return
+	}
+	h.blobs++
+
+	content := strings.Join(f.lines, "\n")
+	violations := h.checker.CheckFile(f.path, content)
+	violations = compliance.ApplySuppressions(violations, map[string]string{f.path: content})
+	for i := range violations {
+		v := &violations[i]
+		// Lines are numbered within the added lines; map them to the file
+		v.LineStart = f.lineNums[v.LineStart-1]
+		v.LineEnd = f.lineNums[v.LineEnd-1]
+		v.Commit = f.commit
+	}
+	// Cap as results come in so a long history does not pile up matches
+	h.violations = h.checker.Limit(append(h.violations, violations...))
+}
+
+// pathViolations checks the paths added over the history against the file
+// pattern rules. Rules that require a file to exist do not apply to history.
+func (h *historyScanner) pathViolations() []compliance.ViolationResult {
+	var paths []string
+	for _, p := range h.addedPaths {
+		if h.reader.PathFilter(p) == "" {
+			paths = append(paths, p)
+		}
+	}
+	var violations []compliance.ViolationResult
+	for _, v := range h.checker.CheckPaths(paths) {
+		if commit := h.pathCommit[v.FilePath]; commit != nil {
+			v.Commit = commit
+			violations = append(violations, v)
+		}
+	}
+	return violations
+}
+
+// hunkStart returns the first line number of the new file in a hunk header
+// such as "@@ -12,0 +13,2 @@".
+func hunkStart(header string) int {
+	i := strings.Index(header, " +")
+	if i < 0 {
+		return 1
+	}
+	rest := header[i+2:]
+	if j := strings.IndexAny(rest, ", "); j >= 0 {
+		rest = rest[:j]
+	}
+	n, err := strconv.Atoi(rest)
+	if err != nil || n < 1 {
+		return 1
+	}
+	return n
+}
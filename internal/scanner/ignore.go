@@ -0,0 +1,243 @@
+package scanner
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// IgnoreFileName is the name of the ignore files read in every directory of a
+// scan target. They use the same syntax as .gitignore.
+const IgnoreFileName = ".nerifectignore"
+
+// ignoreRule is one pattern line of an ignore file.
+type ignoreRule struct {
+	source  string // ignore file and line number, e.g. "src/.nerifectignore:3"
+	pattern string // the line as written
+	negate  bool
+	dirOnly bool
+	base    string // directory of the ignore file relative to the root, "" for the root
+	re      *regexp.Regexp
+}
+
+// String describes the rule for explanations, e.g. ".gitignore:4: *.log".
+func (r *ignoreRule) String() string {
+	return r.source + ": " + r.pattern
+}
+
+// matches reports whether the rule's pattern matches a path relative to the
+// scan root.
+func (r *ignoreRule) matches(relPath string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if r.base != "" {
+		if !strings.HasPrefix(relPath, r.base+"/") {
+			return false
+		}
+		relPath = relPath[len(r.base)+1:]
+	}
+	return r.re.MatchString(relPath)
+}
+
+// ignoreRules holds the rules that apply within one directory: its own ignore
+// files on top of those of its parents. Rules are never modified after
+// loading, so a chain can be shared by concurrent walkers.
+type ignoreRules struct {
+	parent *ignoreRules
+	rules  []*ignoreRule
+}
+
+// match returns the rule that decides whether a path is ignored, or nil if
+// no rule matches. As in git, the last matching rule wins and rules of a
+// deeper directory take precedence over those of its parents.
+func (l *ignoreRules) match(relPath string, isDir bool) *ignoreRule {
+	for ; l != nil; l = l.parent {
+		for i := len(l.rules) - 1; i >= 0; i-- {
+			if l.rules[i].matches(relPath, isDir) {
+				return l.rules[i]
+			}
+		}
+	}
+	return nil
+}
+
+// child returns the rules for a directory with its ignore files loaded on top
+// of l. names lists the ignore files to read, lowest precedence first.
+func (l *ignoreRules) child(rootDir, relDir string, names []string) *ignoreRules {
+	var rules []*ignoreRule
+	for _, name := range names {
+		rules = append(rules, loadIgnoreFile(rootDir, relDir, name)...)
+	}
+	if len(rules) == 0 {
+		return l
+	}
+	return &ignoreRules{parent: l, rules: rules}
+}
+
+// loadIgnoreFile reads the rules of an ignore file in relDir. name may contain
+// slashes, as for .git/info/exclude. A missing file has no rules.
+func loadIgnoreFile(rootDir, relDir, name string) []*ignoreRule {
+	f, err := os.Open(filepath.Join(rootDir, relDir, name))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	source := filepath.ToSlash(filepath.Join(relDir, name))
+	base := filepath.ToSlash(relDir)
+	if base == "." {
+		base = ""
+	}
+
+	var rules []*ignoreRule
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		rule, ok := parseIgnoreLine(scanner.Text())
+		if !ok {
+			continue
+		}
+		rule.source = fmt.Sprintf("%s:%d", source, n)
+		rule.base = base
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// parseIgnoreLine parses one line of an ignore file with .gitignore syntax.
+// It returns false for blank lines, comments and invalid patterns.
+func parseIgnoreLine(line string) (*ignoreRule, bool) {
+	line = strings.TrimSuffix(line, "\r")
+	pattern := trimTrailingSpace(line)
+	if pattern == "" || strings.HasPrefix(pattern, "#") {
+		return nil, false
+	}
+
+	rule := &ignoreRule{pattern: pattern}
+	if strings.HasPrefix(pattern, "!") {
+		rule.negate = true
+		pattern = pattern[1:]
+	} else if strings.HasPrefix(pattern, `\!`) || strings.HasPrefix(pattern, `\#`) {
+		pattern = pattern[1:]
+	}
+	if strings.HasSuffix(pattern, "/") {
+		rule.dirOnly = true
+		pattern = strings.TrimRight(pattern, "/")
+	}
+	if pattern == "" {
+		return nil, false
+	}
+
+	// A slash at the start or in the middle anchors the pattern to the
+	// directory of the ignore file; otherwise it matches at any depth
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	expr := ignorePatternRegexp(pattern)
+	if anchored {
+		expr = "^" + expr + "$"
+	} else {
+		expr = "^(?:.*/)?" + expr + "$"
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, false
+	}
+	rule.re = re
+	return rule, true
+}
+
+// trimTrailingSpace removes trailing spaces unless they are escaped with a
+// backslash.
+func trimTrailingSpace(line string) string {
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+	return line
+}
+
+// ignorePatternRegexp translates a .gitignore glob into a regular expression.
+// "*" and "?" do not match "/", while "**" matches across directories when it
+// makes up a whole path segment: "**/x" matches x in any directory, "x/**"
+// everything inside x and "a/**/b" zero or more directories between a and b.
+func ignorePatternRegexp(pattern string) string {
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch c {
+		case '*':
+			if strings.HasPrefix(pattern[i:], "**") && (i == 0 || pattern[i-1] == '/') {
+				rest := pattern[i+2:]
+				switch {
+				case rest == "":
+					b.WriteString(".*")
+					i++
+					continue
+				case strings.HasPrefix(rest, "/"):
+					b.WriteString("(?:.*/)?")
+					i += 2
+					continue
+				}
+			}
+			for i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+			}
+			b.WriteString("[^/]*")
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			end := classEnd(pattern, i)
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : end]
+			b.WriteByte('[')
+			// As with "*" and "?", a negated class does not match "/"
+			if strings.HasPrefix(class, "!") || strings.HasPrefix(class, "^") {
+				b.WriteString("^/")
+				class = class[1:]
+			}
+			b.WriteString(strings.ReplaceAll(class, `\`, `\\`))
+			b.WriteByte(']')
+			i = end
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	return b.String()
+}
+
+// classEnd returns the index of the "]" closing the bracket expression that
+// starts at i, or -1 if it is not closed. Character classes such as
+// "[:digit:]" are skipped, so their "]" does not close the expression.
+func classEnd(pattern string, i int) int {
+	j := i + 1
+	if j < len(pattern) && (pattern[j] == '!' || pattern[j] == '^') {
+		j++
+	}
+	// A "]" right after the opening bracket is part of the class
+	if j < len(pattern) && pattern[j] == ']' {
+		j++
+	}
+	for ; j < len(pattern); j++ {
+		if strings.HasPrefix(pattern[j:], "[:") {
+			if end := strings.Index(pattern[j+2:], ":]"); end >= 0 {
+				j += end + 3
+				continue
+			}
+		}
+		if pattern[j] == ']' {
+			return j
+		}
+	}
+	return -1
+}
@@ -0,0 +1,201 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseIgnoreLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		path    string
+		isDir   bool
+		want    bool
+	}{
+		// Unanchored patterns match at any depth
+		{"name in root", "*.log", "debug.log", false, true},
+		{"name in subdirectory", "*.log", "a/b/debug.log", false, true},
+		{"star stops at slash", "*.log", "a/b.log/c", false, false},
+		{"question mark", "file?.txt", "file1.txt", false, true},
+		{"question mark not slash", "a?b", "a/b", false, false},
+		{"directory name at any depth", "vendor", "src/vendor", true, true},
+
+		// A leading or middle slash anchors the pattern
+		{"leading slash in root", "/build", "build", true, true},
+		{"leading slash not deeper", "/build", "src/build", true, false},
+		{"middle slash in root", "docs/*.md", "docs/a.md", false, true},
+		{"middle slash not deeper", "docs/*.md", "x/docs/a.md", false, false},
+		{"middle slash star stops at slash", "docs/*.md", "docs/sub/a.md", false, false},
+
+		// Trailing slash matches directories only
+		{"dir-only dir", "tmp/", "tmp", true, true},
+		{"dir-only file", "tmp/", "tmp", false, false},
+		{"dir-only deeper dir", "tmp/", "a/tmp", true, true},
+		{"dir-only anchored", "/tmp/", "a/tmp", true, false},
+
+		// "**" as a whole segment
+		{"leading ** in root", "**/secret.txt", "secret.txt", false, true},
+		{"leading ** deeper", "**/secret.txt", "a/b/secret.txt", false, true},
+		{"leading ** with dir", "**/cfg/*.yaml", "a/cfg/x.yaml", false, true},
+		{"trailing ** inside", "gen/**", "gen/a/b.go", false, true},
+		{"trailing ** not the dir", "gen/**", "gen", true, false},
+		{"trailing ** anchored", "gen/**", "x/gen/a.go", false, false},
+		{"middle ** zero dirs", "a/**/b", "a/b", false, true},
+		{"middle ** one dir", "a/**/b", "a/x/b", false, true},
+		{"middle ** many dirs", "a/**/b", "a/x/y/z/b", false, true},
+		{"middle ** other name", "a/**/b", "a/x/c", false, false},
+		{"** within a name is a star", "a**b", "axxb", false, true},
+		{"** within a name stops at slash", "a**b", "a/b", false, false},
+
+		// Negation
+		{"negated", "!keep.log", "keep.log", false, true},
+
+		// Bracket classes
+		{"range", "file[0-9].txt", "file3.txt", false, true},
+		{"range no match", "file[0-9].txt", "filex.txt", false, false},
+		{"set", "[abc].go", "b.go", false, true},
+		{"negated with !", "[!a].go", "b.go", false, true},
+		{"negated with ! no match", "[!a].go", "a.go", false, false},
+		{"negated with ^", "[^a].go", "a.go", false, false},
+		{"negated not slash", "a[!x]b", "a/b", false, false},
+		{"bracket first in class", "[]x].go", "].go", false, true},
+		{"posix class", "[[:digit:]].go", "7.go", false, true},
+		{"posix class no match", "[[:digit:]].go", "x.go", false, false},
+		{"unclosed bracket is literal", "a[b", "a[b", false, true},
+
+		// Escapes
+		{"escaped star", `a\*b`, "a*b", false, true},
+		{"escaped star is literal", `a\*b`, "axb", false, false},
+		{"escaped question mark", `a\?`, "ab", false, false},
+		{"escaped hash", `\#notes`, "#notes", false, true},
+		{"escaped bang", `\!important`, "!important", false, true},
+		{"escaped trailing space", `name\ `, "name ", false, true},
+		{"unescaped trailing space", "name  ", "name", false, true},
+		{"dot is literal", "*.go", "ago", false, false},
+		{"CRLF line", "*.tmp\r", "x.tmp", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, ok := parseIgnoreLine(tt.pattern)
+			if !ok {
+				t.Fatalf("parseIgnoreLine(%q) returned no rule", tt.pattern)
+			}
+			if got := rule.matches(tt.path, tt.isDir); got != tt.want {
+				t.Errorf("%q matches %q (dir %v) = %v, want %v (regexp %s)", tt.pattern, tt.path, tt.isDir, got, tt.want, rule.re)
+			}
+		})
+	}
+}
+
+func TestParseIgnoreLineFlags(t *testing.T) {
+	tests := []struct {
+		line    string
+		ok      bool
+		negate  bool
+		dirOnly bool
+	}{
+		{"", false, false, false},
+		{"   ", false, false, false},
+		{"# comment", false, false, false},
+		{"!", false, false, false},
+		{"/", false, false, false},
+		{"*.log", true, false, false},
+		{"!*.log", true, true, false},
+		{"build/", true, false, true},
+		{"!build/", true, true, true},
+		{`\!build`, true, false, false},
+		{`\#build`, true, false, false},
+	}
+	for _, tt := range tests {
+		rule, ok := parseIgnoreLine(tt.line)
+		if ok != tt.ok {
+			t.Errorf("parseIgnoreLine(%q) ok = %v, want %v", tt.line, ok, tt.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if rule.negate != tt.negate || rule.dirOnly != tt.dirOnly {
+			t.Errorf("parseIgnoreLine(%q) negate, dirOnly = %v, %v, want %v, %v", tt.line, rule.negate, rule.dirOnly, tt.negate, tt.dirOnly)
+		}
+	}
+}
+
+func TestIgnoreRulesPrecedence(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		".gitignore":                 "*.gen.go\n",
+		IgnoreFileName:               "*.log\n!keep.log\n/top.txt\nsub/skip.txt\ncache/\n",
+		"sub/" + IgnoreFileName:      "!*.log\n/local.txt\nkeep.log\n",
+		"sub/deep/" + IgnoreFileName: "!*.gen.go\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	names := []string{".gitignore", IgnoreFileName}
+	var rootRules *ignoreRules
+	rootRules = rootRules.child(root, "", names)
+	subRules := rootRules.child(root, "sub", names)
+	deepRules := subRules.child(root, "sub/deep", names)
+
+	tests := []struct {
+		name  string
+		rules *ignoreRules
+		path  string
+		isDir bool
+		want  string // pattern of the deciding rule, "" for none
+	}{
+		{"root rule", rootRules, "a.log", false, "*.log"},
+		{"last rule in a file wins", rootRules, "keep.log", false, "!keep.log"},
+		{"no rule", rootRules, "a.go", false, ""},
+		{"anchored to root", rootRules, "top.txt", false, "/top.txt"},
+		{"anchored not deeper", subRules, "sub/top.txt", false, ""},
+		{"path from root", subRules, "sub/skip.txt", false, "sub/skip.txt"},
+		{"dir-only rule", rootRules, "cache", true, "cache/"},
+		{"dir-only rule on file", rootRules, "cache", false, ""},
+		{"child overrides parent", subRules, "sub/a.log", false, "!*.log"},
+		{"child re-ignores", subRules, "sub/keep.log", false, "keep.log"},
+		{"parent applies below child", subRules, "sub/x/a.gen.go", false, "*.gen.go"},
+		{"anchored to child dir", subRules, "sub/local.txt", false, "/local.txt"},
+		{"anchored to child dir not deeper", subRules, "sub/x/local.txt", false, ""},
+		{"grandchild overrides", deepRules, "sub/deep/a.gen.go", false, "!*.gen.go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ""
+			if rule := tt.rules.match(tt.path, tt.isDir); rule != nil {
+				got = rule.pattern
+			}
+			if got != tt.want {
+				t.Errorf("match(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIgnoreFileOverridesGitignore(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, ".gitignore"), []byte("*.env\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, IgnoreFileName), []byte("!prod.env\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var rules *ignoreRules
+	rules = rules.child(root, "", []string{".gitignore", IgnoreFileName})
+	if rule := rules.match("prod.env", false); rule == nil || !rule.negate {
+		t.Errorf("prod.env decided by %v, want the negation in %s", rule, IgnoreFileName)
+	}
+	if rule := rules.match("dev.env", false); rule == nil || rule.negate {
+		t.Errorf("dev.env decided by %v, want the .gitignore rule", rule)
+	}
+}
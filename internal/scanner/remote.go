@@ -0,0 +1,311 @@
+package scanner
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/nerifect/nerifect-cli/internal/config"
+)
+
+// RemoteProvider is a git hosting service whose repositories can be scanned
+// by URL. Each provider has its own access token, and its name is stored as
+// the target_type of its scans.
+type RemoteProvider interface {
+	// Name is the target_type recorded on scans, e.g. "gitlab".
+	Name() string
+	// Label is a human-readable name for messages.
+	Label() string
+	// Hosts lists the hosts the provider serves: its public host plus any
+	// self-hosted ones in the config.
+	Hosts(cfg *config.Config) []string
+	// TokenConfigKey and TokenEnvVar name where the access token is read from.
+	TokenConfigKey() string
+	TokenEnvVar() string
+	// TokenUser is the user name sent with the token over HTTPS.
+	TokenUser() string
+	// ParsePath extracts the repository from the path of a URL on host. It
+	// returns the path to clone over HTTPS and a short name for the repo.
+	ParsePath(host, urlPath string) (clonePath, name string, err error)
+}
+
+// remoteProviders are matched by host, in order. URLs on any other host are
+// handled by gitProvider.
+var remoteProviders = []RemoteProvider{
+	githubProvider{},
+	gitlabProvider{},
+	bitbucketProvider{},
+}
+
+// Remote is a repository URL resolved to its provider.
+type Remote struct {
+	Provider RemoteProvider
+	Host     string
+	// Name is a short name for the repository, e.g. "owner/repo".
+	Name string
+	// cloneURL is the URL cloned, without credentials.
+	cloneURL string
+	// urlUser holds the credentials given in an HTTPS URL, used when the
+	// provider has no token: a user name and password, or a token alone.
+	urlUser *url.Userinfo
+}
+
+// scpURLRe matches the scp-like syntax git accepts for SSH, user@host:path.
+var scpURLRe = regexp.MustCompile(`^([A-Za-z0-9._-]+)@([A-Za-z0-9.-]+):(.+)$`)
+
+// IsRemoteURL reports whether a scan target is a URL rather than a local
+// path: any scheme:// URL, git's user@host:path syntax, or a path starting
+// with the host of a known provider, such as github.com/owner/repo.
+// ParseRemote rejects schemes other than https and ssh.
+func IsRemoteURL(s string, cfg *config.Config) bool {
+	s = strings.TrimSpace(s)
+	if strings.Contains(s, "://") || scpURLRe.MatchString(s) {
+		return true
+	}
+	host, _, _ := strings.Cut(s, "/")
+	return providerForHost(host, cfg) != nil
+}
+
+// ParseRemote resolves a git URL to its provider and the URL to clone.
+// SSH URLs are cloned as given, authenticating with the SSH agent. HTTPS URLs
+// are cloned without credentials, which are passed to git separately by
+// Auth.
+func ParseRemote(target string, cfg *config.Config) (*Remote, error) {
+	target = strings.TrimSpace(target)
+	// Errors show the target without any password it contains
+	shown := scrubSecrets(target, nil)
+
+	var host, urlPath string
+	var user *url.Userinfo
+	ssh := false
+	if m := scpURLRe.FindStringSubmatch(target); m != nil && !strings.Contains(target, "://") {
+		host, urlPath, ssh = m[2], m[3], true
+	} else {
+		raw := target
+		if !strings.Contains(raw, "://") {
+			raw = "https://" + raw
+		}
+		u, err := url.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid git URL %q", shown)
+		}
+		if u.Scheme != "https" && u.Scheme != "ssh" {
+			return nil, fmt.Errorf("unsupported git URL %q: use an https:// or ssh:// URL", shown)
+		}
+		host, urlPath, user, ssh = u.Host, u.Path, u.User, u.Scheme == "ssh"
+	}
+	if host == "" {
+		return nil, fmt.Errorf("invalid git URL %q: no host", shown)
+	}
+
+	provider := providerForHost(host, cfg)
+	if provider == nil {
+		if !IsRemoteURL(target, cfg) {
+			return nil, fmt.Errorf("%q is not a git URL", shown)
+		}
+		provider = gitProvider{}
+	}
+	clonePath, name, err := provider.ParsePath(host, urlPath)
+	if err == nil && clonePath == "" {
+		err = fmt.Errorf("no repository path")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s URL %q: %w", provider.Label(), shown, err)
+	}
+
+	r := &Remote{Provider: provider, Host: host, Name: name, cloneURL: "https://" + host + "/" + clonePath}
+	if ssh {
+		r.cloneURL = target
+	} else if user != nil && user.String() != "" {
+		r.urlUser = user
+	}
+	return r, nil
+}
+
+// HTTPS reports whether the repo is cloned over HTTPS, where tokens apply.
+func (r *Remote) HTTPS() bool {
+	return strings.HasPrefix(r.cloneURL, "https://")
+}
+
+// CloneURL returns the URL to clone. It never contains credentials.
+func (r *Remote) CloneURL() string {
+	return r.cloneURL
+}
+
+// Auth returns the credentials for cloning over HTTPS: the provider's token,
+// or else the credentials given in the URL. A token of the form "user:token"
+// sets its own user name, and a URL with a user name but no password, such as
+// https://TOKEN@github.com/owner/repo, gives a token. Auth returns nil for SSH
+// URLs and when there are no credentials.
+func (r *Remote) Auth(cfg *config.Config) *GitAuth {
+	if !r.HTTPS() {
+		return nil
+	}
+	auth := &GitAuth{scope: "https://" + r.Host + "/"}
+	token, _ := cfg.Get(r.Provider.TokenConfigKey())
+	switch {
+	case token != "":
+		var ok bool
+		if auth.user, auth.password, ok = strings.Cut(token, ":"); !ok {
+			auth.user, auth.password = r.Provider.TokenUser(), token
+		}
+	case r.urlUser != nil:
+		var ok bool
+		if auth.password, ok = r.urlUser.Password(); ok {
+			auth.user = r.urlUser.Username()
+		} else {
+			auth.user, auth.password = r.Provider.TokenUser(), r.urlUser.Username()
+		}
+	default:
+		return nil
+	}
+	return auth
+}
+
+// providerForHost returns the provider serving host, or nil if none does.
+// Ports are ignored, since SSH and HTTPS URLs of a host use different ones.
+func providerForHost(host string, cfg *config.Config) RemoteProvider {
+	if host == "" {
+		return nil
+	}
+	for _, p := range remoteProviders {
+		for _, h := range p.Hosts(cfg) {
+			if strings.EqualFold(hostname(host), hostname(h)) {
+				return p
+			}
+		}
+	}
+	return nil
+}
+
+// hostname strips the port from a host.
+func hostname(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+	return host
+}
+
+// pathSegments splits a URL path into its non-empty segments.
+func pathSegments(urlPath string) []string {
+	var segments []string
+	for _, s := range strings.Split(urlPath, "/") {
+		if s != "" {
+			segments = append(segments, s)
+		}
+	}
+	return segments
+}
+
+// ownerRepo parses the owner/repo paths of GitHub and Bitbucket Cloud,
+// ignoring anything after them such as /tree/main.
+func ownerRepo(urlPath string) (clonePath, name string, err error) {
+	segments := pathSegments(urlPath)
+	if len(segments) < 2 {
+		return "", "", fmt.Errorf("expected owner/repo")
+	}
+	name = segments[0] + "/" + strings.TrimSuffix(segments[1], ".git")
+	return name + ".git", name, nil
+}
+
+type githubProvider struct{}
+
+func (githubProvider) Name() string           { return "github" }
+func (githubProvider) Label() string          { return "GitHub" }
+func (githubProvider) TokenConfigKey() string { return "github_token" }
+func (githubProvider) TokenEnvVar() string    { return "GITHUB_TOKEN" }
+func (githubProvider) TokenUser() string      { return "x-access-token" }
+
+// Hosts includes GitHub Enterprise Server hosts from github_hosts.
+func (githubProvider) Hosts(cfg *config.Config) []string {
+	return append([]string{"github.com"}, cfg.GithubHosts...)
+}
+
+func (githubProvider) ParsePath(host, urlPath string) (string, string, error) {
+	return ownerRepo(urlPath)
+}
+
+type gitlabProvider struct{}
+
+func (gitlabProvider) Name() string           { return "gitlab" }
+func (gitlabProvider) Label() string          { return "GitLab" }
+func (gitlabProvider) TokenConfigKey() string { return "gitlab_token" }
+func (gitlabProvider) TokenEnvVar() string    { return "GITLAB_TOKEN" }
+func (gitlabProvider) TokenUser() string      { return "oauth2" }
+
+func (gitlabProvider) Hosts(cfg *config.Config) []string {
+	return append([]string{"gitlab.com"}, cfg.GitlabHosts...)
+}
+
+// ParsePath accepts projects in nested groups. GitLab separates the project
+// from pages such as /-/tree/main with a "-" segment.
+func (gitlabProvider) ParsePath(host, urlPath string) (string, string, error) {
+	var segments []string
+	for _, s := range pathSegments(urlPath) {
+		if s == "-" {
+			break
+		}
+		segments = append(segments, s)
+	}
+	if len(segments) < 2 {
+		return "", "", fmt.Errorf("expected group/project")
+	}
+	name := strings.TrimSuffix(strings.Join(segments, "/"), ".git")
+	return name + ".git", name, nil
+}
+
+type bitbucketProvider struct{}
+
+func (bitbucketProvider) Name() string           { return "bitbucket" }
+func (bitbucketProvider) Label() string          { return "Bitbucket" }
+func (bitbucketProvider) TokenConfigKey() string { return "bitbucket_token" }
+func (bitbucketProvider) TokenEnvVar() string    { return "BITBUCKET_TOKEN" }
+func (bitbucketProvider) TokenUser() string      { return "x-token-auth" }
+
+// Hosts includes Bitbucket Server and Data Center hosts from bitbucket_hosts.
+func (bitbucketProvider) Hosts(cfg *config.Config) []string {
+	return append([]string{"bitbucket.org"}, cfg.BitbucketHosts...)
+}
+
+// ParsePath accepts Bitbucket Cloud's owner/repo paths and Bitbucket
+// Server's browse (/projects/KEY/repos/repo) and clone (/scm/KEY/repo, or
+// KEY/repo over SSH) paths.
+func (bitbucketProvider) ParsePath(host, urlPath string) (string, string, error) {
+	if strings.EqualFold(host, "bitbucket.org") {
+		return ownerRepo(urlPath)
+	}
+	segments := pathSegments(urlPath)
+	var project, repo string
+	switch {
+	case len(segments) >= 4 && segments[0] == "projects" && segments[2] == "repos":
+		project, repo = segments[1], segments[3]
+	case len(segments) >= 3 && segments[0] == "scm":
+		project, repo = segments[1], segments[2]
+	case len(segments) == 2:
+		// SSH clone URLs have no /scm prefix
+		project, repo = segments[0], segments[1]
+	default:
+		return "", "", fmt.Errorf("expected /projects/KEY/repos/repo or /scm/KEY/repo")
+	}
+	repo = strings.TrimSuffix(repo, ".git")
+	return "scm/" + project + "/" + repo + ".git", project + "/" + repo, nil
+}
+
+// gitProvider handles git URLs on hosts no other provider serves. The URL is
+// cloned as given, with git_token for HTTPS.
+type gitProvider struct{}
+
+func (gitProvider) Name() string                      { return "git" }
+func (gitProvider) Label() string                     { return "git" }
+func (gitProvider) TokenConfigKey() string            { return "git_token" }
+func (gitProvider) TokenEnvVar() string               { return "NERIFECT_GIT_TOKEN" }
+func (gitProvider) TokenUser() string                 { return "git" }
+func (gitProvider) Hosts(cfg *config.Config) []string { return nil }
+
+// ParsePath names the repo after its path without the .git suffix.
+func (gitProvider) ParsePath(host, urlPath string) (string, string, error) {
+	clonePath := strings.Join(pathSegments(urlPath), "/")
+	return clonePath, strings.TrimSuffix(clonePath, ".git"), nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/nerifect/nerifect-cli/internal/ai"
@@ -22,12 +23,16 @@ type ScanResult struct {
 
 // ScanOptions holds optional scan parameters from repo config.
 type ScanOptions struct {
-	Branch    string
-	PolicyIDs []int64
-	DiffBase  string // if set, only scan files changed vs this git ref
+	Branch   string
+	Policies store.PolicySelection // policies for pattern checks and LLM evaluation
+	DiffBase string                // if set, only scan files changed vs this git ref
+	Since    string                // for history scans, only walk commits after this git ref
+	Baseline *compliance.Baseline  // if set, violations in the baseline are marked as baselined
+	NoCache  bool                  // if set, cached LLM evaluations are not used
+	Repo     *config.RepoConfig    // if set, its file selection overrides the global one
 }
 
-// RunScan orchestrates a full scan of a target (local path or GitHub URL).
+// RunScan orchestrates a full scan of a target (local path or git URL).
 func RunScan(ctx context.Context, target string, scanType store.ScanType, cfg *config.Config, opts ScanOptions) (*ScanResult, error) {
 	var (
 		scanDir   string
@@ -36,30 +41,46 @@ func RunScan(ctx context.Context, target string, scanType store.ScanType, cfg *c
 		commitSHA  string
 	)
 
+	// Resolve the policy selection up front so that a selection matching no
+	// policy fails before any work is done
+	var policies []store.Policy
+	if scanType == store.ScanTypeFull || scanType == store.ScanTypeCompliance || scanType == store.ScanTypeHistory {
+		var err error
+		policies, err = store.SelectPolicies(opts.Policies)
+		if err != nil {
+			return nil, fmt.Errorf("selecting policies: %w", err)
+		}
+		if len(policies) == 0 && !opts.Policies.IsEmpty() {
+			return nil, fmt.Errorf("no policies match the selection (%s)", opts.Policies)
+		}
+	}
+
 	// Resolve target
-	if IsGitHubURL(target) {
-		targetType = "github"
-		owner, repo, err := ParseGitHubURL(target)
+	if IsRemoteURL(target, cfg) {
+		remote, err := ParseRemote(target, cfg)
 		if err != nil {
-			return nil, fmt.Errorf("parsing GitHub URL: %w", err)
+			return nil, err
 		}
+		targetType = remote.Provider.Name()
 
-		cloneURL := BuildCloneURL(owner, repo, cfg.GithubToken)
-		scanDir, cleanup, err = CloneRepo(ctx, cloneURL, opts.Branch)
+		// A history scan needs every commit; other scans only the latest
+		depth := 1
+		if scanType == store.ScanTypeHistory {
+			depth = 0
+		}
+		scanDir, cleanup, err = CloneRepo(ctx, remote.CloneURL(), remote.Auth(cfg), opts.Branch, depth)
 		if err != nil {
-			return nil, fmt.Errorf("cloning repo: %w", err)
+			return nil, fmt.Errorf("cloning repo: %w%s", err, tokenHint(remote, cfg))
 		}
 		defer cleanup()
 		commitSHA = GetCloneCommitSHA(scanDir)
+		// Never record a password given in the URL
+		target = RedactURL(target)
 	} else {
 		// Local path
-		absPath := target
-		if !strings.HasPrefix(target, "/") {
-			cwd, _ := os.Getwd()
-			absPath = cwd + "/" + target
-		}
-		if target == "." {
-			absPath, _ = os.Getwd()
+		absPath, err := filepath.Abs(target)
+		if err != nil {
+			return nil, fmt.Errorf("resolving path %q: %w", target, err)
 		}
 		info, err := os.Stat(absPath)
 		if err != nil {
@@ -69,6 +90,9 @@ func RunScan(ctx context.Context, target string, scanType store.ScanType, cfg *c
 			return nil, fmt.Errorf("target %q is not a directory", target)
 		}
 		scanDir = absPath
+		// Record the absolute path so later commands (fix apply, history)
+		// can find the target regardless of the working directory.
+		target = absPath
 	}
 
 	// Create scan record
@@ -76,198 +100,371 @@ func RunScan(ctx context.Context, target string, scanType store.ScanType, cfg *c
 	if err != nil {
 		return nil, fmt.Errorf("creating scan record: %w", err)
 	}
+	if scanType == store.ScanTypeHistory {
+		return runHistoryScan(ctx, scan, scanDir, cfg, opts, policies)
+	}
 
 	// Build file reader
-	var reader *LocalFileReader
+	fileOpts := NewFileOptions(cfg, opts.Repo)
 	if opts.DiffBase != "" {
 		changedFiles, err := GitChangedFiles(scanDir, opts.DiffBase)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "warning: diff mode failed, scanning all files: %v\n", err)
-			reader = NewLocalFileReader(scanDir, cfg.MaxFilesPerScan, cfg.MaxFileSizeKB)
 		} else {
-			allowList := make(map[string]bool, len(changedFiles))
+			fileOpts.AllowList = make(map[string]bool, len(changedFiles))
 			for _, f := range changedFiles {
-				allowList[f] = true
+				fileOpts.AllowList[f] = true
 			}
-			reader = NewLocalFileReaderWithAllowList(scanDir, cfg.MaxFilesPerScan, cfg.MaxFileSizeKB, allowList)
 		}
-	} else {
-		reader = NewLocalFileReader(scanDir, cfg.MaxFilesPerScan, cfg.MaxFileSizeKB)
 	}
+	reader := NewLocalFileReader(scanDir, fileOpts)
 	files, err := reader.ListFiles()
 	if err != nil {
 		store.FailScan(scan.ID)
 		return nil, fmt.Errorf("listing files: %w", err)
 	}
+	if n := reader.Stats().Truncated; n > 0 {
+		fmt.Fprintf(os.Stderr, "warning: %d files over max_files_per_scan (%d) were not scanned\n", n, cfg.MaxFilesPerScan)
+	}
+
+	runAI := scanType == store.ScanTypeFull || scanType == store.ScanTypeAI
+	runCompliance := (scanType == store.ScanTypeFull || scanType == store.ScanTypeCompliance) && len(policies) > 0
+
+	// Stream file contents once through the detector and the pattern checker
+	var (
+		detector       *ai.Detector
+		detectorFiles  map[string]bool
+		pathDetections []ai.Detection
+		checker        *compliance.PatternChecker
+	)
+	if runAI {
+		detector = ai.NewDetector()
+		pathDetections = detector.ScanPaths(files)
+		detectorFiles = detector.ContentFiles(files)
+	}
+	if runCompliance {
+		checker = compliance.NewPatternChecker(store.ExtractRulesFromPolicies(policies), cfg.MaxMatchesPerRule)
+	}
+	streamed := files
+	if checker == nil || !checker.HasCodeRules() {
+		streamed = nil
+		for _, path := range files {
+			if detectorFiles[path] {
+				streamed = append(streamed, path)
+			}
+		}
+	}
+	contentDetections := make([][]ai.Detection, len(streamed))
+	fileViolations := make([][]compliance.ViolationResult, len(streamed))
+	err = reader.StreamFiles(ctx, streamed, 0, func(i int, path, content string) {
+		if detectorFiles[path] {
+			contentDetections[i] = detector.ScanContent(path, content)
+		}
+		if checker != nil {
+			fileViolations[i] = compliance.ApplySuppressions(checker.CheckFile(path, content), map[string]string{path: content})
+		}
+	})
+	if err != nil {
+		store.FailScan(scan.ID)
+		return nil, fmt.Errorf("reading files: %w", err)
+	}
 
 	var allDetections []store.AIDetection
 	var allViolations []store.Violation
 
 	// Phase 1: AI Detection
-	if scanType == store.ScanTypeFull || scanType == store.ScanTypeAI {
-		detector := ai.NewDetector()
-		detections, err := detector.Scan(reader)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "warning: AI detection failed: %v\n", err)
-		} else {
-			for _, d := range detections {
-				det, err := store.CreateAIDetection(
-					scan.ID, d.Name, d.Version, d.Type, d.RiskLevel,
-					d.EUAIActRisk, d.Status, d.FilePath, d.Confidence,
-					d.DetectionMethod, "{}",
-				)
-				if err == nil {
-					allDetections = append(allDetections, *det)
-				}
+	if runAI {
+		detections := detector.Merge(pathDetections, contentDetections)
+		for _, d := range detections {
+			det, err := store.CreateAIDetection(
+				scan.ID, d.Name, d.Version, d.Type, d.RiskLevel,
+				d.EUAIActRisk, d.Status, d.FilePath, d.Confidence,
+				d.DetectionMethod, "{}",
+			)
+			if err == nil {
+				allDetections = append(allDetections, *det)
 			}
+		}
 
-			// LLM-based risk assessment if detections found and API key available
-			if len(detections) > 0 && cfg.ActiveAPIKey() != "" {
-				assessDetections(ctx, cfg, detections, &allDetections)
-			}
+		// LLM-based risk assessment if detections found and API key available
+		if len(detections) > 0 && cfg.HasLLM() {
+			assessDetections(ctx, cfg, scan.ID, detections, &allDetections)
 		}
 	}
 
 	// Phase 2: Compliance scanning
-	if scanType == store.ScanTypeFull || scanType == store.ScanTypeCompliance {
-		// Load policies
-		policies, err := store.ListPolicies()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "warning: loading policies failed: %v\n", err)
+	if runCompliance {
+		// Pattern-based checks
+		patternViolations := checker.CheckPaths(files)
+		var codeViolations []compliance.ViolationResult
+		for _, vs := range fileViolations {
+			codeViolations = append(codeViolations, vs...)
+		}
+		patternViolations = append(patternViolations, checker.Limit(codeViolations)...)
+		patternViolations = compliance.ApplyBaseline(patternViolations, opts.Baseline)
+		for _, ruleID := range checker.CappedRules() {
+			fmt.Fprintf(os.Stderr, "warning: rule %s reached the limit of %d matches, further matches were not reported\n", ruleID, cfg.MaxMatchesPerRule)
 		}
 
-		// Filter policies by IDs if configured
-		if len(opts.PolicyIDs) > 0 && len(policies) > 0 {
-			idSet := make(map[int64]bool, len(opts.PolicyIDs))
-			for _, id := range opts.PolicyIDs {
-				idSet[id] = true
-			}
-			var filtered []store.Policy
-			for _, p := range policies {
-				if idSet[p.ID] {
-					filtered = append(filtered, p)
-				}
+		for _, v := range patternViolations {
+			viol, err := saveViolation(scan.ID, v, "PATTERN")
+			if err == nil {
+				allViolations = append(allViolations, *viol)
 			}
-			policies = filtered
 		}
 
-		if len(policies) > 0 {
-			// Read file contents for scanning
-			fileContents, err := reader.ReadFilesContents()
+		// LLM semantic evaluation
+		if cfg.HasLLM() {
+			llmOpts := cfg.LLMOptions()
+			if ttl := cfg.LLMCacheTTL(); ttl > 0 && !opts.NoCache {
+				llmOpts.Cache = store.NewLLMCache(ttl)
+			}
+			llmClient := llm.New(llmOpts)
+			evaluator := compliance.NewEvaluator(llmClient, cfg.LLMBatchTokens, cfg.LLMMaxConcurrency)
+
+			result, err := evaluator.Evaluate(ctx, policies, files, reader)
+			recordUsage(llmClient, store.UsageOperationEvaluation, scan.ID)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "warning: reading files failed: %v\n", err)
+				// Without the evaluation the score only reflects pattern
+				// rules, so the scan must not be reported as clean.
+				fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+				store.MarkScanDegraded(scan.ID, err.Error())
 			}
+			if result != nil {
+				store.SetScanCoverage(scan.ID, store.ScanCoverage(result.Coverage))
+				if n := len(result.TruncatedFiles); n > 0 {
+					fmt.Fprintf(os.Stderr, "warning: %d files exceed llm_batch_tokens and were only partly evaluated\n", n)
+				}
 
-			// Pattern-based checks
-			rules := store.ExtractRulesFromPolicies(policies)
-			checker := compliance.NewPatternChecker()
-			patternViolations := checker.Check(rules, fileContents, files)
-
-			for _, v := range patternViolations {
-				viol, err := store.CreateViolation(
-					scan.ID, 0, v.PolicyName, v.RuleID, store.Severity(v.Severity),
-					v.Title, v.Description, v.FilePath, v.LineStart, v.LineEnd,
-					v.CodeSnippet, v.ClauseReference, v.Recommendation, "PATTERN",
-				)
-				if err == nil {
-					allViolations = append(allViolations, *viol)
+				// Deduplicate LLM violations against pattern violations
+				existingKeys := make(map[string]bool)
+				for _, v := range allViolations {
+					key := v.RuleID + "|" + v.FilePath
+					existingKeys[key] = true
 				}
-			}
 
-			// LLM semantic evaluation
-			if cfg.ActiveAPIKey() != "" {
-				llmClient := llm.NewClient(cfg.LLMProvider, cfg.ActiveAPIKey(), cfg.DefaultModel)
-				evaluator := compliance.NewEvaluator(llmClient)
-
-				policiesForLLM, _ := store.GetAllPoliciesForScan()
-				result, err := evaluator.Evaluate(ctx, policiesForLLM, fileContents)
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "warning: LLM evaluation failed: %v\n", err)
-				} else if result != nil {
-					// Deduplicate LLM violations against pattern violations
-					existingKeys := make(map[string]bool)
-					for _, v := range allViolations {
-						key := v.RuleID + "|" + v.FilePath
-						existingKeys[key] = true
+				var llmViolations []compliance.ViolationResult
+				for _, v := range result.Violations {
+					key := v.RuleID + "|" + v.FilePath
+					if !existingKeys[key] {
+						llmViolations = append(llmViolations, v)
 					}
+				}
 
-					for _, v := range result.Violations {
-						key := v.RuleID + "|" + v.FilePath
-						if existingKeys[key] {
-							continue
-						}
-						viol, err := store.CreateViolation(
-							scan.ID, 0, v.PolicyName, v.RuleID, store.Severity(v.Severity),
-							v.Title, v.Description, v.FilePath, v.LineStart, v.LineEnd,
-							v.CodeSnippet, v.ClauseReference, v.Recommendation, "LLM",
-						)
-						if err == nil {
-							allViolations = append(allViolations, *viol)
-						}
+				llmViolations = compliance.ApplySuppressions(llmViolations, readViolatedFiles(reader, llmViolations))
+				for _, v := range compliance.ApplyBaseline(llmViolations, opts.Baseline) {
+					viol, err := saveViolation(scan.ID, v, "LLM")
+					if err == nil {
+						allViolations = append(allViolations, *viol)
 					}
 				}
-			} else if scanType == store.ScanTypeCompliance {
-				fmt.Fprintf(os.Stderr, "warning: %s not set, skipping LLM evaluation\n", llm.APIKeyEnvVar(cfg.LLMProvider))
 			}
 		} else if scanType == store.ScanTypeCompliance {
-			fmt.Fprintf(os.Stderr, "warning: no policies loaded, use 'nerifect policy add' to add policies\n")
+			fmt.Fprintf(os.Stderr, "warning: %s not set, skipping LLM evaluation\n", llm.APIKeyEnvVar(cfg.LLMProvider))
 		}
+	} else if scanType == store.ScanTypeCompliance {
+		fmt.Fprintf(os.Stderr, "warning: no policies loaded, use 'nerifect policy add' to add policies\n")
 	}
 
-	// Calculate compliance score
+	score, activeCount := scoreViolations(allViolations)
+
+	// Complete scan
+	stats := reader.Stats()
+	store.SetScanFileStats(scan.ID, store.FileStats{
+		FilesSkipped:   int(stats.Skipped),
+		FilesTruncated: int(stats.Truncated),
+		DirsExcluded:   int(stats.ExcludedDirs),
+		FilesIgnored:   int(stats.Ignored),
+		FilesExcluded:  int(stats.Extension),
+		FilesBinary:    int(stats.Binary),
+	})
+	store.CompleteScan(scan.ID, &score, len(files), activeCount, len(allDetections), commitSHA)
+
+	return finishScan(cfg, scan.ID, allViolations, allDetections), nil
+}
+
+// scoreViolations calculates the compliance score of a scan's violations and
+// counts the active ones.
+func scoreViolations(violations []store.Violation) (int, int) {
 	var violationResults []compliance.ViolationResult
-	for _, v := range allViolations {
+	activeCount := 0
+	for _, v := range violations {
 		violationResults = append(violationResults, compliance.ViolationResult{
-			RuleID:   v.RuleID,
-			Severity: string(v.Severity),
+			RuleID:     v.RuleID,
+			Severity:   string(v.Severity),
+			Suppressed: v.Suppressed,
+			Baselined:  v.Baselined,
+			Unverified: v.Unverified,
 		})
+		if v.IsActive() {
+			activeCount++
+		}
 	}
-	score := compliance.CalculateScore(violationResults)
-
-	// Complete scan
-	store.CompleteScan(scan.ID, &score, len(files), len(allViolations), len(allDetections), commitSHA)
-
-	// Reload scan to get updated fields
-	scan, _ = store.GetScan(scan.ID)
+	return compliance.CalculateScore(violationResults), activeCount
+}
 
+// finishScan reloads a completed scan with its LLM usage.
+func finishScan(cfg *config.Config, scanID int64, violations []store.Violation, detections []store.AIDetection) *ScanResult {
+	scan, _ := store.GetScan(scanID)
+	if usage, err := store.SummarizeLLMUsage(store.UsageFilter{ScanID: scan.ID}); err == nil && len(usage) > 0 {
+		totals := TotalUsage(cfg, usage)
+		scan.Usage = &totals
+	}
 	return &ScanResult{
 		Scan:       scan,
-		Violations: allViolations,
-		Detections: allDetections,
-	}, nil
+		Violations: violations,
+		Detections: detections,
+	}
+}
+
+// saveViolation stores a checker or evaluator result as a violation of the scan.
+func saveViolation(scanID int64, v compliance.ViolationResult, checkType string) (*store.Violation, error) {
+	viol := &store.Violation{
+		ScanID:            scanID,
+		PolicyName:        v.PolicyName,
+		RuleID:            v.RuleID,
+		Severity:          store.Severity(v.Severity),
+		Title:             v.Title,
+		Description:       v.Description,
+		FilePath:          v.FilePath,
+		LineStart:         v.LineStart,
+		LineEnd:           v.LineEnd,
+		ColumnStart:       v.ColumnStart,
+		ColumnEnd:         v.ColumnEnd,
+		CodeSnippet:       v.CodeSnippet,
+		ClauseReference:   v.ClauseReference,
+		Recommendation:    v.Recommendation,
+		CheckType:         checkType,
+		Suppressed:        v.Suppressed,
+		SuppressionReason: v.SuppressionReason,
+		Fingerprint:       compliance.Fingerprint(v.RuleID, v.FilePath, v.CodeSnippet),
+		Baselined:         v.Baselined,
+		Unverified:        v.Unverified,
+	}
+	if v.Commit != nil {
+		viol.CommitSHA = v.Commit.SHA
+		viol.CommitAuthor = v.Commit.Author
+		date := v.Commit.Date
+		viol.CommitDate = &date
+	}
+	return store.CreateViolation(viol)
+}
+
+// readViolatedFiles reads the files named by violations, for checking their
+// suppression comments. Unverified violations may name files that were not
+// scanned, so they are skipped.
+func readViolatedFiles(reader *LocalFileReader, violations []compliance.ViolationResult) map[string]string {
+	contents := make(map[string]string)
+	for _, v := range violations {
+		if _, ok := contents[v.FilePath]; ok || v.Unverified {
+			continue
+		}
+		if content, err := reader.ReadFile(v.FilePath); err == nil {
+			contents[v.FilePath] = content
+		}
+	}
+	return contents
+}
+
+// recordUsage stores the usage of the LLM calls made by client for a scan.
+func recordUsage(client *llm.Client, op store.UsageOperation, scanID int64) {
+	for _, c := range client.TakeCalls() {
+		store.RecordLLMUsage(&store.LLMUsage{
+			Provider:     c.Provider,
+			Model:        c.Model,
+			Operation:    op,
+			InputTokens:  c.Usage.InputTokens,
+			OutputTokens: c.Usage.OutputTokens,
+			ScanID:       scanID,
+		})
+	}
+}
+
+// TotalUsage sums LLM usage and estimates its cost with the configured prices.
+func TotalUsage(cfg *config.Config, usage []store.UsageSummary) store.UsageTotals {
+	var t store.UsageTotals
+	for _, u := range usage {
+		t.Calls += u.Calls
+		t.InputTokens += u.InputTokens
+		t.OutputTokens += u.OutputTokens
+		cost, ok := cfg.EstimateCost(u.Provider, u.Model, llm.Usage{InputTokens: u.InputTokens, OutputTokens: u.OutputTokens})
+		if !ok {
+			t.Unpriced = true
+		}
+		t.EstimatedCost += cost
+	}
+	return t
+}
+
+// OpenScanTarget makes the files of a completed scan available on disk.
+// Local targets are returned as-is; remote targets are fetched again at the
+// scan's commit. The caller must invoke the returned cleanup function.
+func OpenScanTarget(ctx context.Context, scan *store.Scan, cfg *config.Config) (string, func(), error) {
+	if scan.TargetType == "local" {
+		info, err := os.Stat(scan.Target)
+		if err != nil {
+			return "", nil, fmt.Errorf("scan target %q: %w", scan.Target, err)
+		}
+		if !info.IsDir() {
+			return "", nil, fmt.Errorf("scan target %q is not a directory", scan.Target)
+		}
+		return scan.Target, func() {}, nil
+	}
+
+	remote, err := ParseRemote(scan.Target, cfg)
+	if err != nil {
+		return "", nil, err
+	}
+	dir, cleanup, err := CloneRepoAtCommit(ctx, remote.CloneURL(), remote.Auth(cfg), scan.CommitSHA)
+	if err != nil {
+		return "", nil, fmt.Errorf("fetching %s: %w%s", scrubSecrets(scan.Target, nil), err, tokenHint(remote, cfg))
+	}
+	return dir, cleanup, nil
+}
+
+// tokenHint suggests how to authenticate after a failed clone: with the
+// provider's token over HTTPS, or a key in the SSH agent over SSH.
+func tokenHint(remote *Remote, cfg *config.Config) string {
+	if !remote.HTTPS() {
+		return " (SSH URLs authenticate with the keys in your SSH agent; check ssh-add -l)"
+	}
+	if remote.Auth(cfg) != nil {
+		return ""
+	}
+	return fmt.Sprintf(" (private repos need %s or %s)", remote.Provider.TokenConfigKey(), remote.Provider.TokenEnvVar())
 }
 
-func assessDetections(ctx context.Context, cfg *config.Config, detections []ai.Detection, storedDetections *[]store.AIDetection) {
+func assessDetections(ctx context.Context, cfg *config.Config, scanID int64, detections []ai.Detection, storedDetections *[]store.AIDetection) {
 	var lines []string
 	for _, d := range detections {
 		lines = append(lines, fmt.Sprintf("- %s (%s): %s", d.Name, d.Type, d.FilePath))
 	}
 	modelsSummary := strings.Join(lines, "\n")
 
-	llmClient := llm.NewClient(cfg.LLMProvider, cfg.ActiveAPIKey(), cfg.DefaultModel)
+	llmClient := llm.New(cfg.LLMOptions())
 	prompt := llm.BuildAIGovernancePrompt(modelsSummary)
 
-	responseText, err := llmClient.GenerateContent(ctx, prompt)
-	if err != nil {
-		return
+	var response struct {
+		Assessments []struct {
+			Name        string `json:"name"`
+			Status      string `json:"status"`
+			RiskLevel   string `json:"risk_level"`
+			Issues      int    `json:"issues"`
+			EUAIActRisk string `json:"eu_ai_act_risk"`
+			Reasoning   string `json:"reasoning"`
+		} `json:"assessments"`
 	}
 
-	var assessments []struct {
-		Name       string `json:"name"`
-		Status     string `json:"status"`
-		RiskLevel  string `json:"risk_level"`
-		Issues     int    `json:"issues"`
-		EUAIActRisk string `json:"eu_ai_act_risk"`
-		Reasoning  string `json:"reasoning"`
-	}
-
-	if err := llm.ParseJSONResponse(responseText, &assessments); err != nil {
+	err := llmClient.GenerateJSON(ctx, prompt, llm.AIGovernanceSchema, &response)
+	recordUsage(llmClient, store.UsageOperationAIAssessment, scanID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: AI governance assessment failed: %v\n", err)
 		return
 	}
 
 	// Update stored detections with LLM assessments
 	for i, det := range *storedDetections {
-		for _, a := range assessments {
+		for _, a := range response.Assessments {
 			if strings.EqualFold(a.Name, det.Name) {
 				if a.Status != "" {
 					(*storedDetections)[i].Status = a.Status
@@ -0,0 +1,78 @@
+package store
+
+import "time"
+
+// LLMCache is an llm.Cache backed by the llm_cache table. Entries older
+// than TTL are ignored and replaced on the next Put.
+type LLMCache struct {
+	TTL time.Duration
+}
+
+// NewLLMCache returns a cache whose entries are valid for ttl.
+func NewLLMCache(ttl time.Duration) *LLMCache {
+	return &LLMCache{TTL: ttl}
+}
+
+// Get returns the cached response for key if it has not expired.
+func (c *LLMCache) Get(key string) (string, bool) {
+	var response string
+	err := db.QueryRow(
+		`SELECT response FROM llm_cache WHERE key = ? AND created_at >= ?`,
+		key, time.Now().Add(-c.TTL),
+	).Scan(&response)
+	if err != nil {
+		return "", false
+	}
+	db.Exec(`UPDATE llm_cache SET hits = hits + 1, last_hit_at = ? WHERE key = ?`, time.Now(), key)
+	return response, true
+}
+
+// Put stores a response, replacing any earlier entry for key. Errors are
+// ignored since a failed write only costs a later cache miss.
+func (c *LLMCache) Put(key, provider, model, response string) {
+	db.Exec(
+		`INSERT OR REPLACE INTO llm_cache (key, provider, model, response, hits, created_at) VALUES (?, ?, ?, ?, 0, ?)`,
+		key, provider, model, response, time.Now(),
+	)
+}
+
+// GetCacheStats summarizes the cache. Entries older than ttl count as expired.
+func GetCacheStats(ttl time.Duration) (*CacheStats, error) {
+	stats := &CacheStats{}
+	err := db.QueryRow(
+		`SELECT COUNT(*), COALESCE(SUM(created_at < ?), 0), COALESCE(SUM(hits), 0), COALESCE(SUM(LENGTH(response)), 0) FROM llm_cache`,
+		time.Now().Add(-ttl),
+	).Scan(&stats.Entries, &stats.Expired, &stats.Hits, &stats.SizeBytes)
+	if err != nil {
+		return nil, err
+	}
+	if stats.Entries == 0 {
+		return stats, nil
+	}
+
+	var oldest, newest time.Time
+	if err := db.QueryRow(`SELECT created_at FROM llm_cache ORDER BY created_at LIMIT 1`).Scan(&oldest); err != nil {
+		return nil, err
+	}
+	if err := db.QueryRow(`SELECT created_at FROM llm_cache ORDER BY created_at DESC LIMIT 1`).Scan(&newest); err != nil {
+		return nil, err
+	}
+	stats.Oldest, stats.Newest = &oldest, &newest
+	return stats, nil
+}
+
+// ClearCache deletes cache entries and returns how many were removed. With
+// expiredOnly set, only entries older than ttl are deleted.
+func ClearCache(expiredOnly bool, ttl time.Duration) (int64, error) {
+	query := `DELETE FROM llm_cache`
+	var args []interface{}
+	if expiredOnly {
+		query += ` WHERE created_at < ?`
+		args = append(args, time.Now().Add(-ttl))
+	}
+	result, err := db.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -46,6 +46,19 @@ func migrate(db *sql.DB) error {
 		violation_count INTEGER DEFAULT 0,
 		ai_detection_count INTEGER DEFAULT 0,
 		commit_sha TEXT DEFAULT '',
+		degraded_reason TEXT DEFAULT '',
+		llm_files_evaluated INTEGER DEFAULT 0,
+		llm_files_total INTEGER DEFAULT 0,
+		llm_rules_evaluated INTEGER DEFAULT 0,
+		llm_rules_total INTEGER DEFAULT 0,
+		llm_files_partial INTEGER DEFAULT 0,
+		files_skipped INTEGER DEFAULT 0,
+		files_truncated INTEGER DEFAULT 0,
+		dirs_excluded INTEGER DEFAULT 0,
+		files_ignored INTEGER DEFAULT 0,
+		files_excluded INTEGER DEFAULT 0,
+		files_binary INTEGER DEFAULT 0,
+		commits_scanned INTEGER DEFAULT 0,
 		started_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 		completed_at DATETIME
 	);
@@ -76,10 +89,20 @@ func migrate(db *sql.DB) error {
 		file_path TEXT NOT NULL,
 		line_start INTEGER DEFAULT 0,
 		line_end INTEGER DEFAULT 0,
+		column_start INTEGER DEFAULT 0,
+		column_end INTEGER DEFAULT 0,
 		code_snippet TEXT DEFAULT '',
 		clause_reference TEXT DEFAULT '',
 		recommendation TEXT DEFAULT '',
 		check_type TEXT DEFAULT '',
+		suppressed INTEGER DEFAULT 0,
+		suppression_reason TEXT DEFAULT '',
+		fingerprint TEXT DEFAULT '',
+		baselined INTEGER DEFAULT 0,
+		unverified INTEGER DEFAULT 0,
+		commit_sha TEXT DEFAULT '',
+		commit_author TEXT DEFAULT '',
+		commit_date DATETIME,
 		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
 	);
 
@@ -107,9 +130,13 @@ func migrate(db *sql.DB) error {
 		fix_diff TEXT NOT NULL,
 		confidence REAL DEFAULT 0.0,
 		status TEXT NOT NULL DEFAULT 'PENDING',
-		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		reviewed_at DATETIME,
+		review_note TEXT DEFAULT '',
+		applied_at DATETIME
 	);
 
+	CREATE INDEX IF NOT EXISTS idx_scans_target ON scans(target, started_at);
 	CREATE INDEX IF NOT EXISTS idx_violations_scan_id ON violations(scan_id);
 	CREATE INDEX IF NOT EXISTS idx_ai_detections_scan_id ON ai_detections(scan_id);
 	CREATE INDEX IF NOT EXISTS idx_fixes_violation_id ON fixes(violation_id);
@@ -129,8 +156,107 @@ func migrate(db *sql.DB) error {
 	);
 
 	CREATE INDEX IF NOT EXISTS idx_agent_sources_url ON agent_sources(url);
+
+	CREATE TABLE IF NOT EXISTS llm_usage (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		provider TEXT NOT NULL,
+		model TEXT NOT NULL,
+		operation TEXT NOT NULL,
+		input_tokens INTEGER DEFAULT 0,
+		output_tokens INTEGER DEFAULT 0,
+		scan_id INTEGER DEFAULT 0,
+		policy_id INTEGER DEFAULT 0,
+		fix_id INTEGER DEFAULT 0,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_llm_usage_created_at ON llm_usage(created_at);
+	CREATE INDEX IF NOT EXISTS idx_llm_usage_scan_id ON llm_usage(scan_id);
+
+	CREATE TABLE IF NOT EXISTS llm_cache (
+		key TEXT PRIMARY KEY,
+		provider TEXT NOT NULL,
+		model TEXT NOT NULL,
+		response TEXT NOT NULL,
+		hits INTEGER DEFAULT 0,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		last_hit_at DATETIME
+	);
 	`
 
-	_, err := db.Exec(schema)
+	if _, err := db.Exec(schema); err != nil {
+		return err
+	}
+
+	// Columns added after the initial release. CREATE TABLE IF NOT EXISTS
+	// leaves existing tables alone, so older databases are upgraded here.
+	columns := []struct {
+		table, name, definition string
+	}{
+		{"fixes", "applied_at", "DATETIME"},
+		{"fixes", "reviewed_at", "DATETIME"},
+		{"fixes", "review_note", "TEXT DEFAULT ''"},
+		{"violations", "column_start", "INTEGER DEFAULT 0"},
+		{"violations", "column_end", "INTEGER DEFAULT 0"},
+		{"violations", "suppressed", "INTEGER DEFAULT 0"},
+		{"violations", "suppression_reason", "TEXT DEFAULT ''"},
+		{"violations", "fingerprint", "TEXT DEFAULT ''"},
+		{"violations", "baselined", "INTEGER DEFAULT 0"},
+		{"violations", "unverified", "INTEGER DEFAULT 0"},
+		{"violations", "commit_sha", "TEXT DEFAULT ''"},
+		{"violations", "commit_author", "TEXT DEFAULT ''"},
+		{"violations", "commit_date", "DATETIME"},
+		{"scans", "degraded_reason", "TEXT DEFAULT ''"},
+		{"scans", "llm_files_evaluated", "INTEGER DEFAULT 0"},
+		{"scans", "llm_files_total", "INTEGER DEFAULT 0"},
+		{"scans", "llm_rules_evaluated", "INTEGER DEFAULT 0"},
+		{"scans", "llm_rules_total", "INTEGER DEFAULT 0"},
+		{"scans", "llm_files_partial", "INTEGER DEFAULT 0"},
+		{"scans", "files_skipped", "INTEGER DEFAULT 0"},
+		{"scans", "files_truncated", "INTEGER DEFAULT 0"},
+		{"scans", "dirs_excluded", "INTEGER DEFAULT 0"},
+		{"scans", "files_ignored", "INTEGER DEFAULT 0"},
+		{"scans", "files_excluded", "INTEGER DEFAULT 0"},
+		{"scans", "files_binary", "INTEGER DEFAULT 0"},
+		{"scans", "commits_scanned", "INTEGER DEFAULT 0"},
+	}
+	for _, c := range columns {
+		if err := ensureColumn(db, c.table, c.name, c.definition); err != nil {
+			return fmt.Errorf("adding %s.%s: %w", c.table, c.name, err)
+		}
+	}
+	return nil
+}
+
+// ensureColumn adds a column to a table if it does not already exist.
+func ensureColumn(db *sql.DB, table, column, definition string) error {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
 	return err
 }
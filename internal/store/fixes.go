@@ -1,6 +1,32 @@
 package store
 
-import "time"
+import (
+	"database/sql"
+	"time"
+)
+
+const fixColumns = `id, violation_id, scan_id, fix_description, fix_diff, confidence, status, created_at, reviewed_at, review_note, applied_at`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanFix(row rowScanner) (*Fix, error) {
+	f := &Fix{}
+	var reviewedAt, appliedAt sql.NullTime
+	if err := row.Scan(&f.ID, &f.ViolationID, &f.ScanID, &f.FixDescription, &f.FixDiff,
+		&f.Confidence, &f.Status, &f.CreatedAt, &reviewedAt, &f.ReviewNote, &appliedAt); err != nil {
+		return nil, err
+	}
+	if reviewedAt.Valid {
+		f.ReviewedAt = &reviewedAt.Time
+	}
+	if appliedAt.Valid {
+		f.AppliedAt = &appliedAt.Time
+	}
+	return f, nil
+}
 
 func CreateFix(violationID, scanID int64, fixDesc, fixDiff string, confidence float64) (*Fix, error) {
 	now := time.Now()
@@ -24,33 +50,74 @@ func CreateFix(violationID, scanID int64, fixDesc, fixDiff string, confidence fl
 	}, nil
 }
 
+func GetFix(id int64) (*Fix, error) {
+	return scanFix(db.QueryRow(`SELECT `+fixColumns+` FROM fixes WHERE id = ?`, id))
+}
+
 func GetFixesByViolation(violationID int64) ([]Fix, error) {
-	rows, err := db.Query(
-		`SELECT id, violation_id, scan_id, fix_description, fix_diff, confidence, status, created_at FROM fixes WHERE violation_id = ? ORDER BY id DESC`,
-		violationID,
+	return queryFixes(`SELECT `+fixColumns+` FROM fixes WHERE violation_id = ? ORDER BY id DESC`, violationID)
+}
+
+func GetFixesByScan(scanID int64) ([]Fix, error) {
+	return queryFixes(`SELECT `+fixColumns+` FROM fixes WHERE scan_id = ? ORDER BY id DESC`, scanID)
+}
+
+// FixFilter narrows ListFixes. Zero values match everything.
+type FixFilter struct {
+	ScanID int64
+	Status FixStatus
+}
+
+// ListFixes returns fixes matching the filter, newest first.
+func ListFixes(filter FixFilter) ([]Fix, error) {
+	query := `SELECT ` + fixColumns + ` FROM fixes WHERE 1=1`
+	var args []interface{}
+	if filter.ScanID > 0 {
+		query += ` AND scan_id = ?`
+		args = append(args, filter.ScanID)
+	}
+	if filter.Status != "" {
+		query += ` AND status = ?`
+		args = append(args, string(filter.Status))
+	}
+	query += ` ORDER BY id DESC`
+	return queryFixes(query, args...)
+}
+
+// ReviewFix sets a fix's review status and note and records when the review happened.
+func ReviewFix(id int64, status FixStatus, note string) error {
+	result, err := db.Exec(
+		`UPDATE fixes SET status = ?, review_note = ?, reviewed_at = ? WHERE id = ?`,
+		string(status), note, time.Now(), id,
 	)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer rows.Close()
-
-	var fixes []Fix
-	for rows.Next() {
-		f := Fix{}
-		if err := rows.Scan(&f.ID, &f.ViolationID, &f.ScanID, &f.FixDescription, &f.FixDiff,
-			&f.Confidence, &f.Status, &f.CreatedAt); err != nil {
-			return nil, err
-		}
-		fixes = append(fixes, f)
+	n, _ := result.RowsAffected()
+	if n == 0 {
+		return sql.ErrNoRows
 	}
-	return fixes, nil
+	return nil
 }
 
-func GetFixesByScan(scanID int64) ([]Fix, error) {
-	rows, err := db.Query(
-		`SELECT id, violation_id, scan_id, fix_description, fix_diff, confidence, status, created_at FROM fixes WHERE scan_id = ? ORDER BY id DESC`,
-		scanID,
+// MarkFixApplied records that a fix was written to the working tree.
+func MarkFixApplied(id int64) error {
+	result, err := db.Exec(
+		`UPDATE fixes SET status = ?, applied_at = ? WHERE id = ?`,
+		string(FixStatusApplied), time.Now(), id,
 	)
+	if err != nil {
+		return err
+	}
+	n, _ := result.RowsAffected()
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func queryFixes(query string, args ...interface{}) ([]Fix, error) {
+	rows, err := db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -58,12 +125,11 @@ func GetFixesByScan(scanID int64) ([]Fix, error) {
 
 	var fixes []Fix
 	for rows.Next() {
-		f := Fix{}
-		if err := rows.Scan(&f.ID, &f.ViolationID, &f.ScanID, &f.FixDescription, &f.FixDiff,
-			&f.Confidence, &f.Status, &f.CreatedAt); err != nil {
+		f, err := scanFix(rows)
+		if err != nil {
 			return nil, err
 		}
-		fixes = append(fixes, f)
+		fixes = append(fixes, *f)
 	}
 	return fixes, nil
 }
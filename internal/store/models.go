@@ -1,6 +1,9 @@
 package store
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 type PolicyCategory string
 
@@ -30,12 +33,30 @@ const (
 	FixStatusRejected FixStatus = "REJECTED"
 )
 
+// ParseFixStatus converts a case-insensitive status name to a FixStatus.
+func ParseFixStatus(s string) (FixStatus, bool) {
+	switch FixStatus(strings.ToUpper(strings.TrimSpace(s))) {
+	case FixStatusPending:
+		return FixStatusPending, true
+	case FixStatusApproved:
+		return FixStatusApproved, true
+	case FixStatusApplied:
+		return FixStatusApplied, true
+	case FixStatusRejected:
+		return FixStatusRejected, true
+	}
+	return "", false
+}
+
 type ScanType string
 
 const (
 	ScanTypeFull       ScanType = "FULL"
 	ScanTypeCompliance ScanType = "COMPLIANCE"
 	ScanTypeAI         ScanType = "AI"
+	// ScanTypeHistory checks the lines added by each commit in git history
+	// against pattern rules
+	ScanTypeHistory ScanType = "HISTORY"
 )
 
 type ScanStatus string
@@ -50,7 +71,7 @@ const (
 type Scan struct {
 	ID               int64      `json:"id"`
 	Target           string     `json:"target"`
-	TargetType       string     `json:"target_type"` // "local" or "github"
+	TargetType       string     `json:"target_type"` // "local" or the remote provider, e.g. "github"
 	ScanType         ScanType   `json:"scan_type"`
 	Status           ScanStatus `json:"status"`
 	ComplianceScore  *int       `json:"compliance_score"`
@@ -60,6 +81,64 @@ type Scan struct {
 	CommitSHA        string     `json:"commit_sha"`
 	StartedAt        time.Time  `json:"started_at"`
 	CompletedAt      *time.Time `json:"completed_at"`
+
+	// Files the scan found but did not scan
+	FileStats
+
+	// CommitsScanned is the number of commits a history scan walked; its
+	// FilesScanned counts the distinct file versions checked
+	CommitsScanned int `json:"commits_scanned,omitempty"`
+
+	// DegradedReason explains why part of the scan could not be completed,
+	// e.g. unparseable LLM output. A degraded scan must not be read as clean.
+	DegradedReason string `json:"degraded_reason,omitempty"`
+
+	// Coverage of the LLM evaluation; nil if the scan had no LLM evaluation
+	Coverage *ScanCoverage `json:"llm_coverage,omitempty"`
+
+	// Usage is the LLM usage of the scan. It is not stored with the scan and
+	// is only set by callers that load it.
+	Usage *UsageTotals `json:"llm_usage,omitempty"`
+}
+
+// FileStats counts the files a scan found but did not scan, by the filter
+// that left them out.
+type FileStats struct {
+	// FilesSkipped were too large or unreadable, and FilesTruncated were left
+	// out because the scan reached max_files_per_scan
+	FilesSkipped   int `json:"files_skipped"`
+	FilesTruncated int `json:"files_truncated"`
+	// DirsExcluded matched exclude_dirs; the files in them are not counted
+	DirsExcluded  int `json:"dirs_excluded"`
+	FilesIgnored  int `json:"files_ignored"`  // matched by ignore files
+	FilesExcluded int `json:"files_excluded"` // excluded by extension
+	FilesBinary   int `json:"files_binary"`   // binary content
+}
+
+// Excluded is the total of files and directories left out of the scan.
+func (s FileStats) Excluded() int {
+	return s.FilesSkipped + s.FilesTruncated + s.DirsExcluded + s.FilesIgnored + s.FilesExcluded + s.FilesBinary
+}
+
+// ScanCoverage counts the files and rules the LLM evaluation of a scan
+// covered completely. FilesPartial were only partly evaluated because they
+// exceed llm_batch_tokens.
+type ScanCoverage struct {
+	FilesEvaluated int `json:"files_evaluated"`
+	FilesTotal     int `json:"files_total"`
+	RulesEvaluated int `json:"rules_evaluated"`
+	RulesTotal     int `json:"rules_total"`
+	FilesPartial   int `json:"files_partial"`
+}
+
+// IsComplete reports whether every file was evaluated against every rule.
+func (c *ScanCoverage) IsComplete() bool {
+	return c.FilesEvaluated == c.FilesTotal && c.RulesEvaluated == c.RulesTotal
+}
+
+// IsDegraded reports whether part of the scan could not be completed.
+func (s *Scan) IsDegraded() bool {
+	return s.DegradedReason != ""
 }
 
 type Policy struct {
@@ -77,22 +156,43 @@ type Policy struct {
 }
 
 type Violation struct {
-	ID              int64     `json:"id"`
-	ScanID          int64     `json:"scan_id"`
-	PolicyID        int64     `json:"policy_id"`
-	PolicyName      string    `json:"policy_name"`
-	RuleID          string    `json:"rule_id"`
-	Severity        Severity  `json:"severity"`
-	Title           string    `json:"title"`
-	Description     string    `json:"description"`
-	FilePath        string    `json:"file_path"`
-	LineStart       int       `json:"line_start"`
-	LineEnd         int       `json:"line_end"`
-	CodeSnippet     string    `json:"code_snippet"`
-	ClauseReference string    `json:"clause_reference"`
-	Recommendation  string    `json:"recommendation"`
-	CheckType       string    `json:"check_type"`
-	CreatedAt       time.Time `json:"created_at"`
+	ID                int64     `json:"id"`
+	ScanID            int64     `json:"scan_id"`
+	PolicyID          int64     `json:"policy_id"`
+	PolicyName        string    `json:"policy_name"`
+	RuleID            string    `json:"rule_id"`
+	Severity          Severity  `json:"severity"`
+	Title             string    `json:"title"`
+	Description       string    `json:"description"`
+	FilePath          string    `json:"file_path"`
+	LineStart         int       `json:"line_start"`
+	LineEnd           int       `json:"line_end"`
+	ColumnStart       int       `json:"column_start,omitempty"`
+	ColumnEnd         int       `json:"column_end,omitempty"`
+	CodeSnippet       string    `json:"code_snippet"`
+	ClauseReference   string    `json:"clause_reference"`
+	Recommendation    string    `json:"recommendation"`
+	CheckType         string    `json:"check_type"`
+	Suppressed        bool      `json:"suppressed"`
+	SuppressionReason string    `json:"suppression_reason,omitempty"`
+	Fingerprint       string    `json:"fingerprint,omitempty"`
+	Baselined         bool      `json:"baselined"`
+	Unverified        bool      `json:"unverified"`
+	CreatedAt         time.Time `json:"created_at"`
+
+	// Commit that added the violating lines, for violations found by a
+	// history scan
+	CommitSHA    string     `json:"commit_sha,omitempty"`
+	CommitAuthor string     `json:"commit_author,omitempty"`
+	CommitDate   *time.Time `json:"commit_date,omitempty"`
+}
+
+// IsActive reports whether the violation counts toward the score and exit
+// code. Violations silenced by an inline nerifect:ignore comment, present in
+// the scan's baseline or reported by the LLM with a snippet that was not
+// found in the file do not.
+func (v Violation) IsActive() bool {
+	return !v.Suppressed && !v.Baselined && !v.Unverified
 }
 
 type AIDetection struct {
@@ -112,14 +212,17 @@ type AIDetection struct {
 }
 
 type Fix struct {
-	ID             int64     `json:"id"`
-	ViolationID    int64     `json:"violation_id"`
-	ScanID         int64     `json:"scan_id"`
-	FixDescription string    `json:"fix_description"`
-	FixDiff        string    `json:"fix_diff"`
-	Confidence     float64   `json:"confidence"`
-	Status         FixStatus `json:"status"`
-	CreatedAt      time.Time `json:"created_at"`
+	ID             int64      `json:"id"`
+	ViolationID    int64      `json:"violation_id"`
+	ScanID         int64      `json:"scan_id"`
+	FixDescription string     `json:"fix_description"`
+	FixDiff        string     `json:"fix_diff"`
+	Confidence     float64    `json:"confidence"`
+	Status         FixStatus  `json:"status"`
+	CreatedAt      time.Time  `json:"created_at"`
+	ReviewedAt     *time.Time `json:"reviewed_at,omitempty"`
+	ReviewNote     string     `json:"review_note,omitempty"`
+	AppliedAt      *time.Time `json:"applied_at,omitempty"`
 }
 
 type AgentSource struct {
@@ -134,3 +237,58 @@ type AgentSource struct {
 	CreatedAt      time.Time  `json:"created_at"`
 	UpdatedAt      time.Time  `json:"updated_at"`
 }
+
+type UsageOperation string
+
+const (
+	UsageOperationEvaluation       UsageOperation = "EVALUATION"
+	UsageOperationAIAssessment     UsageOperation = "AI_ASSESSMENT"
+	UsageOperationPolicyExtraction UsageOperation = "POLICY_EXTRACTION"
+	UsageOperationFix              UsageOperation = "FIX"
+)
+
+// LLMUsage is the token usage of a single LLM call, linked to the scan,
+// policy or fix it was made for. Unused links are zero.
+type LLMUsage struct {
+	ID           int64          `json:"id"`
+	Provider     string         `json:"provider"`
+	Model        string         `json:"model"`
+	Operation    UsageOperation `json:"operation"`
+	InputTokens  int            `json:"input_tokens"`
+	OutputTokens int            `json:"output_tokens"`
+	ScanID       int64          `json:"scan_id,omitempty"`
+	PolicyID     int64          `json:"policy_id,omitempty"`
+	FixID        int64          `json:"fix_id,omitempty"`
+	CreatedAt    time.Time      `json:"created_at"`
+}
+
+// UsageSummary totals LLM usage for one provider, model and operation.
+type UsageSummary struct {
+	Provider     string         `json:"provider"`
+	Model        string         `json:"model"`
+	Operation    UsageOperation `json:"operation"`
+	Calls        int            `json:"calls"`
+	InputTokens  int            `json:"input_tokens"`
+	OutputTokens int            `json:"output_tokens"`
+}
+
+// UsageTotals sums LLM usage with its estimated cost in US dollars.
+type UsageTotals struct {
+	Calls         int     `json:"calls"`
+	InputTokens   int     `json:"input_tokens"`
+	OutputTokens  int     `json:"output_tokens"`
+	EstimatedCost float64 `json:"estimated_cost_usd"`
+	// Unpriced is set when some calls used a model without a known price
+	// and are missing from EstimatedCost.
+	Unpriced bool `json:"unpriced,omitempty"`
+}
+
+// CacheStats describes the LLM response cache.
+type CacheStats struct {
+	Entries   int        `json:"entries"`
+	Expired   int        `json:"expired"`
+	Hits      int        `json:"hits"`
+	SizeBytes int64      `json:"size_bytes"`
+	Oldest    *time.Time `json:"oldest,omitempty"`
+	Newest    *time.Time `json:"newest,omitempty"`
+}
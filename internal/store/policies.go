@@ -3,6 +3,9 @@ package store
 import (
 	"database/sql"
 	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -73,21 +76,107 @@ func DeletePolicy(id int64) error {
 	return nil
 }
 
-// GetAllPoliciesForScan returns policies in the format needed by the compliance evaluator.
-func GetAllPoliciesForScan() ([]map[string]interface{}, error) {
+// PolicySelection chooses the policies a scan evaluates. A policy is selected
+// when it matches one of Policies (if any) and one of Categories (if any), so
+// an empty selection selects every policy.
+type PolicySelection struct {
+	// Policies are policy IDs, names, preset slugs or regulation types. Names
+	// also match on a case-insensitive substring, so "gdpr" selects
+	// "GDPR Data Protection Basic".
+	Policies []string `json:"policies,omitempty"`
+	// Categories are policy categories such as SECURITY or COMPLIANCE.
+	Categories []string `json:"categories,omitempty"`
+}
+
+// IsEmpty reports whether the selection selects every policy.
+func (s PolicySelection) IsEmpty() bool {
+	return len(s.Policies) == 0 && len(s.Categories) == 0
+}
+
+// String describes the selection for messages.
+func (s PolicySelection) String() string {
+	var parts []string
+	if len(s.Policies) > 0 {
+		parts = append(parts, "policy "+strings.Join(s.Policies, ", "))
+	}
+	if len(s.Categories) > 0 {
+		parts = append(parts, "category "+strings.Join(s.Categories, ", "))
+	}
+	if len(parts) == 0 {
+		return "all policies"
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Matches reports whether the selection selects p.
+func (s PolicySelection) Matches(p Policy) bool {
+	if len(s.Categories) > 0 && !containsFold(s.Categories, string(p.Category)) {
+		return false
+	}
+	if len(s.Policies) == 0 {
+		return true
+	}
+	for _, term := range s.Policies {
+		if policyMatches(p, term) {
+			return true
+		}
+	}
+	return false
+}
+
+// policyMatches reports whether a single policy term refers to p.
+func policyMatches(p Policy, term string) bool {
+	term = strings.TrimSpace(term)
+	if term == "" {
+		return false
+	}
+	if id, err := strconv.ParseInt(strings.TrimPrefix(term, "#"), 10, 64); err == nil {
+		return p.ID == id
+	}
+	if strings.EqualFold(p.RegulationType, term) ||
+		strings.EqualFold(strings.TrimPrefix(p.SourceURL, "builtin://"), term) {
+		return true
+	}
+	return strings.Contains(strings.ToLower(p.Name), strings.ToLower(term))
+}
+
+// SelectPolicies returns the stored policies chosen by sel. It fails if a
+// policy term matches no stored policy, so that a typo or a deleted policy
+// does not silently widen or empty the scan.
+func SelectPolicies(sel PolicySelection) ([]Policy, error) {
 	policies, err := ListPolicies()
 	if err != nil {
 		return nil, err
 	}
-	var result []map[string]interface{}
+	for _, term := range sel.Policies {
+		found := false
+		for _, p := range policies {
+			if policyMatches(p, term) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no policy matches %q (see 'nerifect policy list')", term)
+		}
+	}
+
+	var selected []Policy
 	for _, p := range policies {
-		result = append(result, map[string]interface{}{
-			"id":         p.ID,
-			"name":       p.Name,
-			"rules_json": p.RulesJSON,
-		})
+		if sel.Matches(p) {
+			selected = append(selected, p)
+		}
 	}
-	return result, nil
+	return selected, nil
+}
+
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(strings.TrimSpace(v), s) {
+			return true
+		}
+	}
+	return false
 }
 
 // ExtractRulesFromPolicies extracts flat rule list from policies for pattern scanning.
@@ -105,6 +194,8 @@ type PolicyRule struct {
 	Topic           string
 	SourceExcerpt   string
 	Recommendations []string
+	AppliesTo       []string
+	Exclude         []string
 }
 
 func ExtractRulesFromPolicies(policies []Policy) []PolicyRule {
@@ -127,17 +218,6 @@ func ExtractRulesFromPolicies(policies []Policy) []PolicyRule {
 				continue
 			}
 
-			var recs []string
-			if v, ok := r["recommendations"]; ok {
-				if arr, ok := v.([]interface{}); ok {
-					for _, item := range arr {
-						if s, ok := item.(string); ok {
-							recs = append(recs, s)
-						}
-					}
-				}
-			}
-
 			rules = append(rules, PolicyRule{
 				PolicyID:        p.ID,
 				PolicyName:      p.Name,
@@ -151,7 +231,9 @@ func ExtractRulesFromPolicies(policies []Policy) []PolicyRule {
 				ClauseReference: strVal(r, "clause_reference", "clauseReference"),
 				Topic:           strVal(r, "topic"),
 				SourceExcerpt:   strVal(r, "source_excerpt", "sourceExcerpt"),
-				Recommendations: recs,
+				Recommendations: strList(r, "recommendations"),
+				AppliesTo:       strList(r, "applies_to", "appliesTo"),
+				Exclude:         strList(r, "exclude"),
 			})
 		}
 	}
@@ -169,6 +251,23 @@ func strVal(m map[string]interface{}, keys ...string) string {
 	return ""
 }
 
+func strList(m map[string]interface{}, keys ...string) []string {
+	for _, k := range keys {
+		arr, ok := m[k].([]interface{})
+		if !ok {
+			continue
+		}
+		var out []string
+		for _, item := range arr {
+			if s, ok := item.(string); ok && s != "" {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
 func strValOr(m map[string]interface{}, fallback string, keys ...string) string {
 	v := strVal(m, keys...)
 	if v == "" {
@@ -43,16 +43,52 @@ func FailScan(id int64) error {
 	return err
 }
 
-func GetScan(id int64) (*Scan, error) {
-	row := db.QueryRow(`SELECT id, target, target_type, scan_type, status, compliance_score, files_scanned, violation_count, ai_detection_count, commit_sha, started_at, completed_at FROM scans WHERE id = ?`, id)
+// SetScanCoverage records how much of a scan the LLM evaluation covered.
+func SetScanCoverage(id int64, c ScanCoverage) error {
+	_, err := db.Exec(
+		`UPDATE scans SET llm_files_evaluated = ?, llm_files_total = ?, llm_rules_evaluated = ?, llm_rules_total = ?, llm_files_partial = ? WHERE id = ?`,
+		c.FilesEvaluated, c.FilesTotal, c.RulesEvaluated, c.RulesTotal, c.FilesPartial, id,
+	)
+	return err
+}
+
+// SetScanFileStats records how many files a scan found but did not scan.
+func SetScanFileStats(id int64, s FileStats) error {
+	_, err := db.Exec(
+		`UPDATE scans SET files_skipped = ?, files_truncated = ?, dirs_excluded = ?, files_ignored = ?, files_excluded = ?, files_binary = ? WHERE id = ?`,
+		s.FilesSkipped, s.FilesTruncated, s.DirsExcluded, s.FilesIgnored, s.FilesExcluded, s.FilesBinary, id,
+	)
+	return err
+}
+
+// SetScanCommits records how many commits a history scan walked.
+func SetScanCommits(id int64, commits int) error {
+	_, err := db.Exec(`UPDATE scans SET commits_scanned = ? WHERE id = ?`, commits, id)
+	return err
+}
+
+// MarkScanDegraded records why part of a scan could not be completed.
+func MarkScanDegraded(id int64, reason string) error {
+	_, err := db.Exec(`UPDATE scans SET degraded_reason = ? WHERE id = ?`, reason, id)
+	return err
+}
+
+const scanColumns = `id, target, target_type, scan_type, status, compliance_score, files_scanned, violation_count, ai_detection_count, commit_sha, started_at, completed_at, degraded_reason, llm_files_evaluated, llm_files_total, llm_rules_evaluated, llm_rules_total, llm_files_partial, files_skipped, files_truncated, dirs_excluded, files_ignored, files_excluded, files_binary, commits_scanned`
+
+func scanScan(row rowScanner) (*Scan, error) {
 	s := &Scan{}
 	var completedAt sql.NullTime
 	var score sql.NullInt64
-	err := row.Scan(&s.ID, &s.Target, &s.TargetType, &s.ScanType, &s.Status, &score,
-		&s.FilesScanned, &s.ViolationCount, &s.AIDetectionCount, &s.CommitSHA, &s.StartedAt, &completedAt)
-	if err != nil {
+	var coverage ScanCoverage
+	if err := row.Scan(&s.ID, &s.Target, &s.TargetType, &s.ScanType, &s.Status, &score,
+		&s.FilesScanned, &s.ViolationCount, &s.AIDetectionCount, &s.CommitSHA, &s.StartedAt, &completedAt, &s.DegradedReason,
+		&coverage.FilesEvaluated, &coverage.FilesTotal, &coverage.RulesEvaluated, &coverage.RulesTotal, &coverage.FilesPartial,
+		&s.FilesSkipped, &s.FilesTruncated, &s.DirsExcluded, &s.FilesIgnored, &s.FilesExcluded, &s.FilesBinary, &s.CommitsScanned); err != nil {
 		return nil, err
 	}
+	if coverage.FilesTotal > 0 || coverage.RulesTotal > 0 {
+		s.Coverage = &coverage
+	}
 	if completedAt.Valid {
 		s.CompletedAt = &completedAt.Time
 	}
@@ -63,11 +99,57 @@ func GetScan(id int64) (*Scan, error) {
 	return s, nil
 }
 
+func GetScan(id int64) (*Scan, error) {
+	return scanScan(db.QueryRow(`SELECT `+scanColumns+` FROM scans WHERE id = ?`, id))
+}
+
 func ListScans(limit int) ([]Scan, error) {
 	if limit <= 0 {
 		limit = 20
 	}
-	rows, err := db.Query(`SELECT id, target, target_type, scan_type, status, compliance_score, files_scanned, violation_count, ai_detection_count, commit_sha, started_at, completed_at FROM scans ORDER BY id DESC LIMIT ?`, limit)
+	return queryScans(`SELECT `+scanColumns+` FROM scans ORDER BY id DESC LIMIT ?`, limit)
+}
+
+// ScanFilter narrows FindScans. Zero values match everything.
+type ScanFilter struct {
+	Target      string
+	Status      ScanStatus
+	ExcludeType ScanType  // scans of any other type
+	Since       time.Time // scans started at or after
+	Until       time.Time // scans started before
+}
+
+// FindScans returns the scans matching filter, oldest first.
+func FindScans(filter ScanFilter) ([]Scan, error) {
+	query := `SELECT ` + scanColumns + ` FROM scans WHERE 1=1`
+	var args []interface{}
+	if filter.Target != "" {
+		query += ` AND target = ?`
+		args = append(args, filter.Target)
+	}
+	if filter.Status != "" {
+		query += ` AND status = ?`
+		args = append(args, string(filter.Status))
+	}
+	if filter.ExcludeType != "" {
+		query += ` AND scan_type != ?`
+		args = append(args, string(filter.ExcludeType))
+	}
+	if !filter.Since.IsZero() {
+		// started_at is stored as text in local time, so compare in the same zone
+		query += ` AND started_at >= ?`
+		args = append(args, filter.Since.Local())
+	}
+	if !filter.Until.IsZero() {
+		query += ` AND started_at < ?`
+		args = append(args, filter.Until.Local())
+	}
+	query += ` ORDER BY started_at, id`
+	return queryScans(query, args...)
+}
+
+func queryScans(query string, args ...interface{}) ([]Scan, error) {
+	rows, err := db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -75,21 +157,11 @@ func ListScans(limit int) ([]Scan, error) {
 
 	var scans []Scan
 	for rows.Next() {
-		s := Scan{}
-		var completedAt sql.NullTime
-		var score sql.NullInt64
-		if err := rows.Scan(&s.ID, &s.Target, &s.TargetType, &s.ScanType, &s.Status, &score,
-			&s.FilesScanned, &s.ViolationCount, &s.AIDetectionCount, &s.CommitSHA, &s.StartedAt, &completedAt); err != nil {
+		s, err := scanScan(rows)
+		if err != nil {
 			return nil, err
 		}
-		if completedAt.Valid {
-			s.CompletedAt = &completedAt.Time
-		}
-		if score.Valid {
-			v := int(score.Int64)
-			s.ComplianceScore = &v
-		}
-		scans = append(scans, s)
+		scans = append(scans, *s)
 	}
-	return scans, nil
+	return scans, rows.Err()
 }
@@ -0,0 +1,59 @@
+package store
+
+import "time"
+
+// RecordLLMUsage stores the usage of one LLM call.
+func RecordLLMUsage(u *LLMUsage) error {
+	if u.CreatedAt.IsZero() {
+		u.CreatedAt = time.Now()
+	}
+	result, err := db.Exec(
+		`INSERT INTO llm_usage (provider, model, operation, input_tokens, output_tokens, scan_id, policy_id, fix_id, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		u.Provider, u.Model, string(u.Operation), u.InputTokens, u.OutputTokens, u.ScanID, u.PolicyID, u.FixID, u.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	u.ID, _ = result.LastInsertId()
+	return nil
+}
+
+// UsageFilter narrows SummarizeLLMUsage. Zero values match everything.
+type UsageFilter struct {
+	ScanID int64
+	Since  time.Time // calls made at or after
+}
+
+// SummarizeLLMUsage totals the matching LLM calls by provider, model and
+// operation, ordered by provider and model.
+func SummarizeLLMUsage(filter UsageFilter) ([]UsageSummary, error) {
+	query := `SELECT provider, model, operation, COUNT(*), COALESCE(SUM(input_tokens), 0), COALESCE(SUM(output_tokens), 0)
+		FROM llm_usage WHERE 1=1`
+	var args []interface{}
+	if filter.ScanID > 0 {
+		query += ` AND scan_id = ?`
+		args = append(args, filter.ScanID)
+	}
+	if !filter.Since.IsZero() {
+		// created_at is stored as text in local time, so compare in the same zone
+		query += ` AND created_at >= ?`
+		args = append(args, filter.Since.Local())
+	}
+	query += ` GROUP BY provider, model, operation ORDER BY provider, model, operation`
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var summaries []UsageSummary
+	for rows.Next() {
+		var u UsageSummary
+		if err := rows.Scan(&u.Provider, &u.Model, &u.Operation, &u.Calls, &u.InputTokens, &u.OutputTokens); err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, u)
+	}
+	return summaries, rows.Err()
+}
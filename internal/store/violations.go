@@ -1,40 +1,49 @@
 package store
 
-import "time"
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
 
-func CreateViolation(scanID, policyID int64, policyName, ruleID string, severity Severity, title, description, filePath string, lineStart, lineEnd int, codeSnippet, clauseRef, recommendation, checkType string) (*Violation, error) {
-	now := time.Now()
+const violationColumns = `id, scan_id, policy_id, policy_name, rule_id, severity, title, description, file_path, line_start, line_end, column_start, column_end, code_snippet, clause_reference, recommendation, check_type, suppressed, suppression_reason, fingerprint, baselined, unverified, created_at, commit_sha, commit_author, commit_date`
+
+func scanViolation(row rowScanner) (*Violation, error) {
+	v := &Violation{}
+	var commitDate sql.NullTime
+	if err := row.Scan(&v.ID, &v.ScanID, &v.PolicyID, &v.PolicyName, &v.RuleID, &v.Severity,
+		&v.Title, &v.Description, &v.FilePath, &v.LineStart, &v.LineEnd, &v.ColumnStart, &v.ColumnEnd,
+		&v.CodeSnippet, &v.ClauseReference, &v.Recommendation, &v.CheckType, &v.Suppressed, &v.SuppressionReason, &v.Fingerprint, &v.Baselined, &v.Unverified, &v.CreatedAt,
+		&v.CommitSHA, &v.CommitAuthor, &commitDate); err != nil {
+		return nil, err
+	}
+	if commitDate.Valid {
+		v.CommitDate = &commitDate.Time
+	}
+	return v, nil
+}
+
+// CreateViolation inserts v and returns a copy with its ID and creation time set.
+func CreateViolation(v *Violation) (*Violation, error) {
+	created := *v
+	created.CreatedAt = time.Now()
 	result, err := db.Exec(
-		`INSERT INTO violations (scan_id, policy_id, policy_name, rule_id, severity, title, description, file_path, line_start, line_end, code_snippet, clause_reference, recommendation, check_type, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-		scanID, policyID, policyName, ruleID, string(severity), title, description, filePath, lineStart, lineEnd, codeSnippet, clauseRef, recommendation, checkType, now,
+		`INSERT INTO violations (scan_id, policy_id, policy_name, rule_id, severity, title, description, file_path, line_start, line_end, column_start, column_end, code_snippet, clause_reference, recommendation, check_type, suppressed, suppression_reason, fingerprint, baselined, unverified, created_at, commit_sha, commit_author, commit_date) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		created.ScanID, created.PolicyID, created.PolicyName, created.RuleID, string(created.Severity), created.Title, created.Description,
+		created.FilePath, created.LineStart, created.LineEnd, created.ColumnStart, created.ColumnEnd,
+		created.CodeSnippet, created.ClauseReference, created.Recommendation, created.CheckType, created.Suppressed, created.SuppressionReason, created.Fingerprint, created.Baselined, created.Unverified, created.CreatedAt,
+		created.CommitSHA, created.CommitAuthor, created.CommitDate,
 	)
 	if err != nil {
 		return nil, err
 	}
-	id, _ := result.LastInsertId()
-	return &Violation{
-		ID:              id,
-		ScanID:          scanID,
-		PolicyID:        policyID,
-		PolicyName:      policyName,
-		RuleID:          ruleID,
-		Severity:        severity,
-		Title:           title,
-		Description:     description,
-		FilePath:        filePath,
-		LineStart:       lineStart,
-		LineEnd:         lineEnd,
-		CodeSnippet:     codeSnippet,
-		ClauseReference: clauseRef,
-		Recommendation:  recommendation,
-		CheckType:       checkType,
-		CreatedAt:       now,
-	}, nil
+	created.ID, _ = result.LastInsertId()
+	return &created, nil
 }
 
 func GetViolationsByScan(scanID int64) ([]Violation, error) {
 	rows, err := db.Query(
-		`SELECT id, scan_id, policy_id, policy_name, rule_id, severity, title, description, file_path, line_start, line_end, code_snippet, clause_reference, recommendation, check_type, created_at FROM violations WHERE scan_id = ? ORDER BY CASE severity WHEN 'CRITICAL' THEN 0 WHEN 'HIGH' THEN 1 WHEN 'MEDIUM' THEN 2 WHEN 'LOW' THEN 3 ELSE 4 END, id`,
+		`SELECT `+violationColumns+` FROM violations WHERE scan_id = ? ORDER BY CASE severity WHEN 'CRITICAL' THEN 0 WHEN 'HIGH' THEN 1 WHEN 'MEDIUM' THEN 2 WHEN 'LOW' THEN 3 ELSE 4 END, id`,
 		scanID,
 	)
 	if err != nil {
@@ -44,28 +53,50 @@ func GetViolationsByScan(scanID int64) ([]Violation, error) {
 
 	var violations []Violation
 	for rows.Next() {
-		v := Violation{}
-		if err := rows.Scan(&v.ID, &v.ScanID, &v.PolicyID, &v.PolicyName, &v.RuleID, &v.Severity,
-			&v.Title, &v.Description, &v.FilePath, &v.LineStart, &v.LineEnd, &v.CodeSnippet,
-			&v.ClauseReference, &v.Recommendation, &v.CheckType, &v.CreatedAt); err != nil {
+		v, err := scanViolation(rows)
+		if err != nil {
 			return nil, err
 		}
-		violations = append(violations, v)
+		violations = append(violations, *v)
 	}
 	return violations, nil
 }
 
 func GetViolation(id int64) (*Violation, error) {
-	row := db.QueryRow(
-		`SELECT id, scan_id, policy_id, policy_name, rule_id, severity, title, description, file_path, line_start, line_end, code_snippet, clause_reference, recommendation, check_type, created_at FROM violations WHERE id = ?`,
-		id,
+	return scanViolation(db.QueryRow(`SELECT `+violationColumns+` FROM violations WHERE id = ?`, id))
+}
+
+// CountViolationsBySeverity returns the number of active violations per
+// severity for each of the given scans. Suppressed, baselined and unverified
+// violations are not counted.
+func CountViolationsBySeverity(scanIDs []int64) (map[int64]map[Severity]int, error) {
+	counts := make(map[int64]map[Severity]int, len(scanIDs))
+	if len(scanIDs) == 0 {
+		return counts, nil
+	}
+	args := make([]interface{}, len(scanIDs))
+	for i, id := range scanIDs {
+		args[i] = id
+		counts[id] = make(map[Severity]int)
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(scanIDs)), ", ")
+	rows, err := db.Query(
+		`SELECT scan_id, UPPER(severity), COUNT(*) FROM violations WHERE scan_id IN (`+placeholders+`) AND suppressed = 0 AND baselined = 0 AND unverified = 0 GROUP BY scan_id, UPPER(severity)`,
+		args...,
 	)
-	v := &Violation{}
-	err := row.Scan(&v.ID, &v.ScanID, &v.PolicyID, &v.PolicyName, &v.RuleID, &v.Severity,
-		&v.Title, &v.Description, &v.FilePath, &v.LineStart, &v.LineEnd, &v.CodeSnippet,
-		&v.ClauseReference, &v.Recommendation, &v.CheckType, &v.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
-	return v, nil
+	defer rows.Close()
+
+	for rows.Next() {
+		var scanID int64
+		var severity Severity
+		var n int
+		if err := rows.Scan(&scanID, &severity, &n); err != nil {
+			return nil, err
+		}
+		counts[scanID][severity] = n
+	}
+	return counts, rows.Err()
 }